@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,29 +13,102 @@ import (
 
 	"backend/internal/config"
 	"backend/internal/db"
-	"backend/internal/models"
+	"backend/internal/mailer"
+	"backend/internal/metrics"
+	"backend/internal/middlewares"
 	"backend/internal/routes"
+	"backend/internal/seed"
+	"backend/internal/sms"
+	"backend/internal/store/postgres"
 	"backend/internal/utils"
 )
 
 func main() {
+	// JSON logs; the standard log package is routed through the same handler
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
 	cfg := config.LoadConfig()
 	log.Printf("Starting Bhinno backend in %s mode...", cfg.APP_ENV)
 
-	db := db.Init(cfg)
-	defer db.Close()
+	pool, migrated := db.Init(cfg)
+	defer func() {
+		pool.Close()
+		log.Println("Postgres pool closed")
+	}()
+
+	stores := postgres.New(pool)
+	metrics.RegisterPool(pool)
+	metrics.RegisterStores(stores)
+
+	superadminEmail, err := utils.NormalizeEmail(cfg.SuperAdminEmail)
+	if err != nil {
+		log.Fatalf("Invalid SUPERADMIN_EMAIL: %v", err)
+	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	superadminPasswordHash := utils.HashPassword(cfg.SuperAdminPassword)
-	models.EnsureSuperAdmin(cfg.SuperAdminEmail, superadminPasswordHash)
+	if err := stores.Users.EnsureSuperAdmin(ctx, superadminEmail, superadminPasswordHash); err != nil {
+		log.Fatalf("Failed to ensure superadmin: %v", err)
+	}
+	cancel()
+	log.Println("Superadmin ensured")
+
+	if cfg.SeedLocationsOnBoot {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		changes, err := seed.Locations(ctx, stores.Locations, cfg.LocationsDir, false)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to seed locations: %v", err)
+		}
+		for _, c := range changes {
+			log.Printf("Locations %s %s", c.CountryCode, c.Action)
+		}
+	}
 
-	utils.InitJWT(cfg.JWTKey, cfg.AccessTokenTTL)
+	// Only after a boot migration; otherwise cmd/migrate up backfills
+	if len(migrated) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		backfilled, err := postgres.BackfillSearchSkeletons(ctx, pool)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to backfill service search: %v", err)
+		}
+		if backfilled > 0 {
+			log.Printf("Search skeletons backfilled for %d services", backfilled)
+		}
+	}
+
+	if err := utils.InitJWT(utils.JWTOptions{
+		Secret:     cfg.JWTKey,
+		KeyDir:     cfg.JWTKeyDir,
+		ActiveKID:  cfg.JWTActiveKID,
+		Issuer:     cfg.JWTIssuer,
+		Audience:   cfg.JWTAudience,
+		TTLMinutes: cfg.AccessTokenTTL,
+	}); err != nil {
+		log.Fatalf("Cannot load JWT keys: %v", err)
+	}
 	utils.InitRefreshTokenTTL(cfg.RefreshTokenTTL)
 
-	mux := routes.RegisterRoutes()
+	mail := mailer.New(cfg.Mailer, cfg.MailDir)
+
+	sender := sms.New(cfg.SMSSender)
+
+	api := routes.NewServer(cfg, stores, mail, sender)
+	api.RefreshSuggestions(time.Duration(cfg.SuggestRefresh) * time.Second)
+	api.AddReadinessCheck("postgres", pool.Ping)
+	api.AddReadinessCheck("migrations", func(ctx context.Context) error {
+		pending, err := db.PendingMigrations(ctx, pool)
+		if err == nil && pending > 0 {
+			err = fmt.Errorf("%d pending migrations", pending)
+		}
+		return err
+	})
 
 	srv := &http.Server{
-		Addr:    cfg.HTTPServer.Address,
-		Handler: mux,
+		Addr:        cfg.HTTPServer.Address,
+		Handler:     middlewares.RequestID(middlewares.LogRequests(middlewares.Instrument(api.RegisterRoutes()))),
+		BaseContext: api.BaseContext,
 	}
 
 	go func() {
@@ -43,21 +118,33 @@ func main() {
 		}
 	}()
 
-	gracefulShutdown(srv, 10*time.Second)
+	gracefulShutdown(srv, api, time.Duration(cfg.ShutdownDrainDelay)*time.Second, time.Duration(cfg.ShutdownTimeout)*time.Second)
 }
 
-func gracefulShutdown(srv *http.Server, timeout time.Duration) {
+// gracefulShutdown first fails readiness for drainDelay while still serving,
+// so load balancers move traffic away. It then stops accepting requests and
+// lets in-flight ones and their background work finish within timeout. Whatever is still running then has
+// its context cancelled, and this returns only once it has all stopped, so
+// the pool can be closed safely afterwards.
+func gracefulShutdown(srv *http.Server, api *routes.Server, drainDelay, timeout time.Duration) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	sig := <-quit
 	log.Printf("Received signal %s, shutting down...", sig)
 
+	api.Drain()
+	time.Sleep(drainDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Drain timed out, cancelling in-flight requests: %v", err)
+	}
+	if err := api.Shutdown(ctx); err != nil {
+		log.Printf("Server exited after cancelling unfinished work: %v", err)
+		return
 	}
 
 	log.Println("Server exited gracefully")
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const usage = `Usage: jwtkey [-dir path] [-alg eddsa|rs256] <kid>
+
+Writes a new private signing key to <dir>/<kid>.pem. To rotate, generate a
+key, point JWT_ACTIVE_KID at it and restart; keep the previous file until the
+tokens it signed have expired, then delete it.
+`
+
+func main() {
+	dir := flag.String("dir", "config/jwt", "key directory (JWT_KEY_DIR)")
+	alg := flag.String("alg", "eddsa", "eddsa or rs256")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	kid := flag.Arg(0)
+	if kid != filepath.Base(kid) {
+		log.Fatalf("Invalid kid %q", kid)
+	}
+
+	var key any
+	var err error
+	switch *alg {
+	case "eddsa":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "rs256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		log.Fatalf("Unknown algorithm %q", *alg)
+	}
+	if err != nil {
+		log.Fatalf("Cannot generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Cannot encode key: %v", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("Cannot create %s: %v", *dir, err)
+	}
+
+	path := filepath.Join(*dir, kid+".pem")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatalf("Cannot write key: %v", err)
+	}
+	defer f.Close()
+
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		log.Fatalf("Cannot write key: %v", err)
+	}
+	fmt.Println(path)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/db"
+	"backend/internal/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `Usage: migrate [-config path] [-dir path] <command> [args]
+
+Commands:
+  up [n]         apply all pending migrations, or the next n
+  down [n]       revert the last n migrations (default 1)
+  status         list migrations and whether they are applied
+  create <name>  write an empty up/down pair for the next version into -dir
+`
+
+func main() {
+	dir := flag.String("dir", "internal/db/migrations", "migrations source directory (for create)")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("create requires a migration name")
+		}
+		up, down, err := db.CreateMigration(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Cannot create migration: %v", err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	cfg := config.LoadConfig()
+	pool := db.Connect(cfg)
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx, pool, steps(args, 0))
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		backfill(ctx, pool)
+
+	case "down":
+		reverted, err := db.MigrateDown(ctx, pool, steps(args, 1))
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+
+	case "status":
+		states, err := db.MigrationStatus(ctx, pool)
+		if err != nil {
+			log.Fatalf("Cannot read migration status: %v", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range states {
+			status, appliedAt := "pending", ""
+			if s.AppliedAt != nil {
+				status, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+			}
+			if s.Missing {
+				status = "applied (missing files)"
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		tw.Flush()
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// backfill fills in the data the application derives for new columns, once
+// every migration is applied.
+func backfill(ctx context.Context, pool *pgxpool.Pool) {
+	pending, err := db.PendingMigrations(ctx, pool)
+	if err != nil {
+		log.Fatalf("Cannot read migration status: %v", err)
+	}
+	if pending > 0 {
+		fmt.Printf("%d migrations still pending, backfill skipped\n", pending)
+		return
+	}
+
+	n, err := postgres.BackfillSearchSkeletons(ctx, pool)
+	if err != nil {
+		log.Fatalf("Search skeleton backfill failed: %v", err)
+	}
+	if n > 0 {
+		fmt.Printf("backfilled search skeletons for %d services\n", n)
+	}
+}
+
+func steps(args []string, def int) int {
+	if len(args) < 2 {
+		return def
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 {
+		log.Fatalf("Invalid step count %q", args[1])
+	}
+	return n
+}
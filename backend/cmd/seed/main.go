@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/db"
+	"backend/internal/seed"
+	"backend/internal/store/postgres"
+)
+
+const usage = `Usage: seed [-config path] [-dir path] [-dry-run]
+
+Validates the location data under -dir and upserts every country into the
+locations table. Countries that already match are left alone, so it is safe
+to run repeatedly.
+`
+
+func main() {
+	dir := flag.String("dir", "", "locations directory (default LOCATIONS_DIR)")
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	if *dir == "" {
+		*dir = cfg.LocationsDir
+	}
+
+	pool := db.Connect(cfg)
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	changes, err := seed.Locations(ctx, postgres.New(pool).Locations, *dir, *dryRun)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNTRY\tACTION\tSTATES\tAREAS\tSUB-AREAS")
+	for _, c := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", c.CountryCode, c.Action, c.States, c.Areas, c.SubAreas)
+	}
+	tw.Flush()
+
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	if *dryRun {
+		fmt.Println("dry run, nothing written")
+	}
+}
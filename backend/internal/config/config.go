@@ -15,20 +15,63 @@ type HTTPServer struct {
 type Config struct {
 	APP_ENV string `env:"APP_ENV"`
 	DB_URL  string `env:"DB_URL"`
+	// Apply pending migrations when the API boots
+	MigrateOnBoot bool `env:"MIGRATE_ON_BOOT" env-default:"true"`
+	// Upsert the location data under LOCATIONS_DIR when the API boots
+	SeedLocationsOnBoot bool   `env:"SEED_LOCATIONS_ON_BOOT" env-default:"false"`
+	LocationsDir        string `env:"LOCATIONS_DIR" env-default:"locations"`
 	HTTPServer
-	JWTKey             string `env:"JWT_KEY"`
+	// HS256 secret, used only when JWT_KEY_DIR is unset
+	JWTKey string `env:"JWT_KEY"`
+	// Directory of <kid>.pem RSA or Ed25519 keys; JWT_ACTIVE_KID signs, the rest only verify
+	JWTKeyDir          string `env:"JWT_KEY_DIR"`
+	JWTActiveKID       string `env:"JWT_ACTIVE_KID"`
+	JWTIssuer          string `env:"JWT_ISSUER" env-default:"bhinno"`
+	JWTAudience        string `env:"JWT_AUDIENCE" env-default:"bhinno-api"`
 	AccessTokenTTL     int    `env:"ACCESS_TOKEN_TTL_MIN" env-default:"15"`
 	RefreshTokenTTL    int    `env:"REFRESH_TOKEN_TTL_DAYS" env-default:"30"`
 	SuperAdminEmail    string `env:"SUPERADMIN_EMAIL"`
 	SuperAdminPassword string `env:"SUPERADMIN_PASSWORD"`
+	// "log" or "file"
+	Mailer           string `env:"MAILER" env-default:"log"`
+	MailDir          string `env:"MAIL_DIR" env-default:"tmp/mail"`
+	PasswordResetURL string `env:"PASSWORD_RESET_URL"`
+	PasswordResetTTL int    `env:"PASSWORD_RESET_TTL_MIN" env-default:"30"`
+	EmailVerifyURL   string `env:"EMAIL_VERIFY_URL"`
+	EmailVerifyTTL   int    `env:"EMAIL_VERIFY_TTL_HOURS" env-default:"24"`
+	// Bearer token required on /metrics when set
+	MetricsToken string `env:"METRICS_TOKEN"`
+	// Handler deadline; ROUTE_TIMEOUTS_SEC overrides it per route pattern
+	RequestTimeout  int            `env:"REQUEST_TIMEOUT_SEC" env-default:"5"`
+	RouteTimeouts   map[string]int `env:"ROUTE_TIMEOUTS_SEC" env-default:"POST /api/auth/google:10,GET /api/admin/audit:120"`
+	ShutdownTimeout int            `env:"SHUTDOWN_TIMEOUT_SEC" env-default:"10"`
+	// How long /readyz fails before the listener closes, so load balancers drain first
+	ShutdownDrainDelay int `env:"SHUTDOWN_DRAIN_DELAY_SEC" env-default:"5"`
+	// Only "log" for now
+	SMSSender       string `env:"SMS_SENDER" env-default:"log"`
+	OTPTTL          int    `env:"OTP_TTL_MIN" env-default:"5"`
+	OTPMaxAttempts  int    `env:"OTP_MAX_ATTEMPTS" env-default:"5"`
+	OTPResendPeriod int    `env:"OTP_RESEND_COOLDOWN_SEC" env-default:"60"`
+	// Codes sent and wrong codes tried per phone within OTP_LIMIT_WINDOW_HOURS
+	OTPMaxSends    int `env:"OTP_MAX_SENDS" env-default:"5"`
+	OTPMaxFailures int `env:"OTP_MAX_FAILURES" env-default:"10"`
+	OTPLimitWindow int `env:"OTP_LIMIT_WINDOW_HOURS" env-default:"24"`
+	// HMAC key for stored OTP codes; must differ from JWT_KEY
+	OTPSecret string `env:"OTP_SECRET"`
+	// How often the /api/suggest index is rebuilt from the database
+	SuggestRefresh int `env:"SUGGEST_REFRESH_SEC" env-default:"300"`
 }
 
+// Registered at package level so commands can add their own flags and parse them together
+var configPath = flag.String("config", "", "path to .env file")
+
 func LoadConfig() *Config {
 	var cfg Config
 
-	var envPath string
-	flag.StringVar(&envPath, "config", "", "path to .env file")
-	flag.Parse()
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	envPath := *configPath
 
 	if envPath == "" {
 		envPath = os.Getenv("CONFIG_PATH")
@@ -42,8 +85,14 @@ func LoadConfig() *Config {
 		log.Fatalf("Cannot read config from %s: %v", envPath, err)
 	}
 
-	if cfg.JWTKey == "" || cfg.SuperAdminEmail == "" || cfg.SuperAdminPassword == "" || cfg.DB_URL == "" {
-		log.Fatal("JWT_KEY, SUPERADMIN_EMAIL, SUPERADMIN_PASSWORD, and DB_URL must be set")
+	if cfg.JWTKey == "" && cfg.JWTKeyDir == "" {
+		log.Fatal("JWT_KEY or JWT_KEY_DIR must be set")
+	}
+	if cfg.SuperAdminEmail == "" || cfg.SuperAdminPassword == "" || cfg.DB_URL == "" {
+		log.Fatal("SUPERADMIN_EMAIL, SUPERADMIN_PASSWORD, and DB_URL must be set")
+	}
+	if cfg.OTPSecret == "" || cfg.OTPSecret == cfg.JWTKey {
+		log.Fatal("OTP_SECRET must be set and differ from JWT_KEY")
 	}
 
 	return &cfg
@@ -0,0 +1,140 @@
+-- Baseline schema. Statements are idempotent so databases created by the old
+-- boot-time createTables adopt this version without changes.
+
+-- Locations
+CREATE TABLE IF NOT EXISTS locations (
+	country_code VARCHAR(8) PRIMARY KEY,
+	country_name VARCHAR(64) UNIQUE NOT NULL,
+	country_flag VARCHAR(16) UNIQUE,
+	states JSONB,
+	administrative_areas JSONB,
+	sub_administrative_areas JSONB,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Users
+CREATE TABLE IF NOT EXISTS users (
+	id BIGSERIAL PRIMARY KEY,
+	verified BOOLEAN DEFAULT FALSE,
+	role VARCHAR(16) NOT NULL DEFAULT 'client'
+		CHECK (role IN ('superadmin', 'admin', 'client')),
+	status VARCHAR(16) NOT NULL DEFAULT 'review'
+		CHECK (status IN ('active', 'review', 'suspended', 'banned')),
+	name VARCHAR(32),
+	avatar VARCHAR(512),
+	bio VARCHAR(512),
+	phone VARCHAR(24) UNIQUE,
+	email VARCHAR(64) UNIQUE,
+	password VARCHAR(512),
+	reset_token VARCHAR(256),
+	reset_token_expiry TIMESTAMPTZ,
+	google_id VARCHAR(128) UNIQUE,
+	google_id_token VARCHAR(128),
+	google_access_token VARCHAR(128),
+	fcm_token VARCHAR(128),
+	refresh_token VARCHAR(128),
+	refresh_token_at TIMESTAMPTZ,
+	rating_avg NUMERIC(3,2) NOT NULL DEFAULT 0.00,
+	rating_count INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Categories
+CREATE TABLE IF NOT EXISTS categories (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(32) NOT NULL,
+	description VARCHAR(128) NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Sub-categories
+CREATE TABLE IF NOT EXISTS sub_categories (
+	id BIGSERIAL PRIMARY KEY,
+	category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+	name VARCHAR(64) NOT NULL,
+	description VARCHAR(128) NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Services
+CREATE TABLE IF NOT EXISTS services (
+	id BIGSERIAL PRIMARY KEY,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+	subcategory_id BIGINT NOT NULL REFERENCES sub_categories(id) ON DELETE RESTRICT,
+	country_code VARCHAR(8) NOT NULL REFERENCES locations(country_code),
+	state_id BIGINT NOT NULL,
+	administrative_area_id BIGINT NOT NULL,
+	sub_administrative_area_id BIGINT NOT NULL,
+	area VARCHAR(256) NOT NULL,
+	title VARCHAR(64) NOT NULL,
+	caption VARCHAR(256) NOT NULL,
+	description VARCHAR(1024) NOT NULL,
+	price VARCHAR(32) NOT NULL,
+	features JSONB CHECK (features IS NULL OR (jsonb_typeof(features) = 'object' AND length(features::text) <= 4096)),
+	hours VARCHAR(16) CHECK (hours IS NULL OR hours = 'All day' OR hours ~ '^([01]?[0-9]|2[0-3]):[0-5][0-9]-([01]?[0-9]|2[0-3]):[0-5][0-9]$'),
+	days TEXT[] NOT NULL CHECK (ARRAY(SELECT unnest(days) EXCEPT SELECT unnest(ARRAY['mon','tue','wed','thu','fri','sat','sun'])) = '{}' AND length(array_to_string(days,',')) <= 32),
+	page_name VARCHAR(32),
+	page_link VARCHAR(256),
+	messenger_name VARCHAR(32),
+	messenger_link VARCHAR(256),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Bookings
+CREATE TABLE IF NOT EXISTS bookings (
+	id BIGSERIAL PRIMARY KEY,
+	service_id BIGINT NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	provider_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	hours VARCHAR(16) CHECK (hours IS NULL OR hours = 'All day' OR hours ~ '^([01]?[0-9]|2[0-3]):[0-5][0-9]-([01]?[0-9]|2[0-3]):[0-5][0-9]$'),
+	days TEXT[] NOT NULL CHECK (ARRAY(SELECT unnest(days) EXCEPT SELECT unnest(ARRAY['mon','tue','wed','thu','fri','sat','sun'])) = '{}' AND length(array_to_string(days,',')) <= 32),
+	status VARCHAR(16) NOT NULL DEFAULT 'pending'
+		CHECK (status IN ('pending', 'confirmed', 'completed', 'cancelled')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Ratings
+CREATE TABLE IF NOT EXISTS ratings (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	provider_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	service_id BIGINT NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+	rating NUMERIC(3,2) NOT NULL CHECK (rating >= 0 AND rating <= 5),
+	comment VARCHAR(1024),
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Ratings are tied to the completed booking they review
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS booking_id BIGINT REFERENCES bookings(id) ON DELETE CASCADE;
+
+-- Users
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_google_id ON users(google_id);
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users(phone);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+CREATE INDEX IF NOT EXISTS idx_users_refresh_token ON users(refresh_token);
+
+-- Services
+CREATE INDEX IF NOT EXISTS idx_services_user_id ON services(user_id);
+CREATE INDEX IF NOT EXISTS idx_services_category_id ON services(category_id);
+CREATE INDEX IF NOT EXISTS idx_services_subcategory_id ON services(subcategory_id);
+CREATE INDEX IF NOT EXISTS idx_services_created_at ON services(created_at);
+CREATE INDEX IF NOT EXISTS idx_services_country_location_active
+	ON services(country_code, state_id, administrative_area_id, sub_administrative_area_id)
+	WHERE active = TRUE;
+CREATE INDEX IF NOT EXISTS idx_services_location ON services(country_code, state_id, administrative_area_id, sub_administrative_area_id);
+CREATE INDEX IF NOT EXISTS idx_services_features ON services USING GIN(features);
+CREATE INDEX IF NOT EXISTS idx_services_days ON services USING GIN(days);
+
+-- Bookings
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_provider_id ON bookings(provider_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_service_id ON bookings(service_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_status ON bookings(status);
+
+-- Ratings
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ratings_booking_id ON ratings(booking_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_provider_id ON ratings(provider_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_service_id ON ratings(service_id);
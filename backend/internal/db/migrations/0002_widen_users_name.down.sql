@@ -0,0 +1,2 @@
+UPDATE users SET name = LEFT(name, 32) WHERE LENGTH(name) > 32;
+ALTER TABLE users ALTER COLUMN name TYPE VARCHAR(32);
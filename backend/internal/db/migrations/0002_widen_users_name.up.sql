@@ -0,0 +1,2 @@
+-- 32 characters is too short for many full names, including Google profile names.
+ALTER TABLE users ALTER COLUMN name TYPE VARCHAR(64);
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_email_lowercase;
+
+DROP INDEX IF EXISTS idx_users_reset_token;
+DROP INDEX IF EXISTS idx_users_verify_token;
+
+ALTER TABLE users DROP COLUMN IF EXISTS verify_token_expiry;
+ALTER TABLE users DROP COLUMN IF EXISTS verify_token;
@@ -0,0 +1,38 @@
+ALTER TABLE users ADD COLUMN verify_token VARCHAR(256);
+ALTER TABLE users ADD COLUMN verify_token_expiry TIMESTAMPTZ;
+
+CREATE INDEX idx_users_verify_token ON users(verify_token);
+CREATE INDEX idx_users_reset_token ON users(reset_token);
+
+-- The superadmin is provisioned from config and never goes through verification
+UPDATE users SET verified = TRUE WHERE role = 'superadmin';
+
+-- Other existing password accounts stay unverified: nothing ever proved they
+-- own their address. Their next login is refused until they follow a link
+-- from /api/auth/verify/resend or reset their password.
+
+-- Sign-in looks up the lowercased address, so stored ones must be lowercase.
+-- Addresses differing only in case belong to one mailbox; such accounts have
+-- to be merged by hand before this can run.
+DO $$
+DECLARE
+	clashes TEXT;
+BEGIN
+	SELECT string_agg(address || ' (users ' || ids || ')', '; ')
+	INTO clashes
+	FROM (
+		SELECT lower(btrim(email)) AS address, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+		FROM users
+		WHERE email IS NOT NULL
+		GROUP BY 1
+		HAVING COUNT(*) > 1
+	) same_mailbox;
+
+	IF clashes IS NOT NULL THEN
+		RAISE EXCEPTION 'emails differing only in case: %', clashes;
+	END IF;
+END $$;
+
+UPDATE users SET email = lower(btrim(email)) WHERE email <> lower(btrim(email));
+
+ALTER TABLE users ADD CONSTRAINT chk_users_email_lowercase CHECK (email = lower(email));
@@ -0,0 +1,2 @@
+-- Normalized phone numbers stay; the original spellings are not kept
+DROP TABLE IF EXISTS phone_otps;
@@ -0,0 +1,42 @@
+-- One pending code per phone; requesting a new code replaces the old one.
+-- sends and failures count since window_started_at and survive the replace.
+CREATE TABLE phone_otps (
+	phone VARCHAR(24) PRIMARY KEY,
+	code_hash VARCHAR(64) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	sends INT NOT NULL DEFAULT 1,
+	failures INT NOT NULL DEFAULT 0,
+	window_started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Rewrite stored phone numbers into the +8801XXXXXXXXX form that
+-- utils.NormalizeBDPhone gives lookups, so phone login finds accounts saved
+-- in local spellings. Numbers that do not parse are left alone, and so are
+-- numbers that would collide with another account's; those need merging by
+-- hand.
+WITH cleaned AS (
+	SELECT id, regexp_replace(phone, '[[:space:]().-]', '', 'g') AS p
+	FROM users
+	WHERE phone IS NOT NULL
+), local AS (
+	SELECT id, CASE
+		WHEN p LIKE '+880%' THEN '0' || substr(p, 5)
+		WHEN p LIKE '00880%' THEN '0' || substr(p, 6)
+		WHEN p LIKE '880%' THEN '0' || substr(p, 4)
+		ELSE p
+	END AS p
+	FROM cleaned
+), normalized AS (
+	SELECT id, '+88' || p AS phone
+	FROM local
+	WHERE p ~ '^01[3-9][0-9]{8}$'
+)
+UPDATE users u
+SET phone = n.phone
+FROM normalized n
+WHERE u.id = n.id
+	AND u.phone <> n.phone
+	AND NOT EXISTS (SELECT 1 FROM users o WHERE o.phone = n.phone AND o.id <> n.id)
+	AND (SELECT COUNT(*) FROM normalized d WHERE d.phone = n.phone) = 1;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS refresh_token VARCHAR(128);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS refresh_token_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_refresh_token ON users(refresh_token);
+
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,30 @@
+-- One row per signed-in device. Refresh tokens rotate on every use and are
+-- stored hashed; a used token is kept so presenting it again can be detected
+-- as reuse and the whole session revoked.
+CREATE TABLE sessions (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	device_name VARCHAR(64),
+	user_agent VARCHAR(256),
+	ip VARCHAR(64),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+CREATE TABLE refresh_tokens (
+	token_hash VARCHAR(64) PRIMARY KEY,
+	session_id BIGINT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	used_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+-- Existing single-device tokens are dropped; those users sign in again.
+DROP INDEX IF EXISTS idx_users_refresh_token;
+ALTER TABLE users DROP COLUMN IF EXISTS refresh_token;
+ALTER TABLE users DROP COLUMN IF EXISTS refresh_token_at;
@@ -0,0 +1,6 @@
+-- Who changed a user's status last, when and why
+ALTER TABLE users ADD COLUMN status_reason VARCHAR(512);
+ALTER TABLE users ADD COLUMN status_changed_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN status_changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_users_created_at ON users(created_at);
@@ -0,0 +1,5 @@
+UPDATE users SET role = 'client' WHERE role = 'provider';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+	CHECK (role IN ('superadmin', 'admin', 'client'));
@@ -0,0 +1,8 @@
+-- Providers publish services; clients only book them. Everyone who already
+-- owns a service becomes a provider so nobody loses access.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+	CHECK (role IN ('superadmin', 'admin', 'provider', 'client'));
+
+UPDATE users SET role = 'provider'
+WHERE role = 'client' AND id IN (SELECT user_id FROM services);
@@ -0,0 +1,32 @@
+-- Append-only record of admin and ownership-sensitive actions. actor_id has
+-- no foreign key so events outlive the users they mention.
+CREATE TABLE audit_events (
+	id BIGSERIAL PRIMARY KEY,
+	actor_id BIGINT,
+	actor_role VARCHAR(16),
+	action VARCHAR(64) NOT NULL,
+	target_type VARCHAR(32) NOT NULL,
+	target_id VARCHAR(64) NOT NULL,
+	diff JSONB,
+	ip VARCHAR(64),
+	request_id VARCHAR(64),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_target ON audit_events(target_type, target_id);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+	BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+	BEFORE TRUNCATE ON audit_events
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
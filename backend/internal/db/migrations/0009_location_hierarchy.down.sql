@@ -0,0 +1,30 @@
+ALTER TABLE services
+	DROP CONSTRAINT IF EXISTS fk_services_state,
+	DROP CONSTRAINT IF EXISTS fk_services_administrative_area,
+	DROP CONSTRAINT IF EXISTS fk_services_sub_administrative_area;
+
+ALTER TABLE locations
+	ADD COLUMN states JSONB,
+	ADD COLUMN administrative_areas JSONB,
+	ADD COLUMN sub_administrative_areas JSONB;
+
+UPDATE locations l SET
+	states = (
+		SELECT jsonb_build_object('states', jsonb_agg(jsonb_build_object('id', id, 'name', name) ORDER BY id))
+		FROM states WHERE country_code = l.country_code
+		HAVING COUNT(*) > 0
+	),
+	administrative_areas = (
+		SELECT jsonb_build_object('administrative_areas', jsonb_agg(jsonb_build_object('id', id, 'state_id', state_id, 'name', name) ORDER BY id))
+		FROM administrative_areas WHERE country_code = l.country_code
+		HAVING COUNT(*) > 0
+	),
+	sub_administrative_areas = (
+		SELECT jsonb_build_object('sub_administrative_areas', jsonb_agg(jsonb_build_object('id', id, 'administrative_area_id', administrative_area_id, 'name', name) ORDER BY id))
+		FROM sub_administrative_areas WHERE country_code = l.country_code
+		HAVING COUNT(*) > 0
+	);
+
+DROP TABLE IF EXISTS sub_administrative_areas;
+DROP TABLE IF EXISTS administrative_areas;
+DROP TABLE IF EXISTS states;
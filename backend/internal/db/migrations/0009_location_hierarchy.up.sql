@@ -0,0 +1,75 @@
+-- The location hierarchy moves out of the JSONB columns into tables. IDs are
+-- only unique within a country, so every key includes the country code.
+CREATE TABLE states (
+	country_code VARCHAR(8) NOT NULL REFERENCES locations(country_code) ON DELETE CASCADE,
+	id BIGINT NOT NULL,
+	name VARCHAR(128) NOT NULL,
+	PRIMARY KEY (country_code, id)
+);
+
+CREATE TABLE administrative_areas (
+	country_code VARCHAR(8) NOT NULL,
+	id BIGINT NOT NULL,
+	state_id BIGINT NOT NULL,
+	name VARCHAR(128) NOT NULL,
+	PRIMARY KEY (country_code, id),
+	UNIQUE (country_code, state_id, id),
+	FOREIGN KEY (country_code, state_id) REFERENCES states(country_code, id) ON DELETE CASCADE
+);
+
+CREATE TABLE sub_administrative_areas (
+	country_code VARCHAR(8) NOT NULL,
+	id BIGINT NOT NULL,
+	administrative_area_id BIGINT NOT NULL,
+	name VARCHAR(128) NOT NULL,
+	PRIMARY KEY (country_code, id),
+	UNIQUE (country_code, administrative_area_id, id),
+	FOREIGN KEY (country_code, administrative_area_id) REFERENCES administrative_areas(country_code, id) ON DELETE CASCADE
+);
+
+-- Each JSONB column held an object with a single array under a key that
+-- varies between countries. Entries whose parent is missing are dropped.
+INSERT INTO states (country_code, id, name)
+SELECT l.country_code, (e->>'id')::BIGINT, e->>'name'
+FROM locations l,
+	jsonb_each(CASE WHEN jsonb_typeof(l.states) = 'object' THEN l.states ELSE '{}' END) AS lists(key, list),
+	jsonb_array_elements(CASE WHEN jsonb_typeof(lists.list) = 'array' THEN lists.list ELSE '[]' END) AS e
+ON CONFLICT DO NOTHING;
+
+INSERT INTO administrative_areas (country_code, id, state_id, name)
+SELECT l.country_code, (e->>'id')::BIGINT, (e->>'state_id')::BIGINT, e->>'name'
+FROM locations l,
+	jsonb_each(CASE WHEN jsonb_typeof(l.administrative_areas) = 'object' THEN l.administrative_areas ELSE '{}' END) AS lists(key, list),
+	jsonb_array_elements(CASE WHEN jsonb_typeof(lists.list) = 'array' THEN lists.list ELSE '[]' END) AS e
+WHERE EXISTS (SELECT 1 FROM states s WHERE s.country_code = l.country_code AND s.id = (e->>'state_id')::BIGINT)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO sub_administrative_areas (country_code, id, administrative_area_id, name)
+SELECT l.country_code, (e->>'id')::BIGINT, (e->>'administrative_area_id')::BIGINT, e->>'name'
+FROM locations l,
+	jsonb_each(CASE WHEN jsonb_typeof(l.sub_administrative_areas) = 'object' THEN l.sub_administrative_areas ELSE '{}' END) AS lists(key, list),
+	jsonb_array_elements(CASE WHEN jsonb_typeof(lists.list) = 'array' THEN lists.list ELSE '[]' END) AS e
+WHERE EXISTS (SELECT 1 FROM administrative_areas a WHERE a.country_code = l.country_code AND a.id = (e->>'administrative_area_id')::BIGINT)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE locations
+	DROP COLUMN states,
+	DROP COLUMN administrative_areas,
+	DROP COLUMN sub_administrative_areas;
+
+-- Services must sit in a consistent chain of their country's areas. Existing
+-- rows are not checked so the migration cannot fail on old data; run
+-- VALIDATE CONSTRAINT once they are cleaned up.
+ALTER TABLE services
+	ADD CONSTRAINT fk_services_state
+		FOREIGN KEY (country_code, state_id)
+		REFERENCES states(country_code, id) NOT VALID,
+	ADD CONSTRAINT fk_services_administrative_area
+		FOREIGN KEY (country_code, state_id, administrative_area_id)
+		REFERENCES administrative_areas(country_code, state_id, id) NOT VALID,
+	ADD CONSTRAINT fk_services_sub_administrative_area
+		FOREIGN KEY (country_code, administrative_area_id, sub_administrative_area_id)
+		REFERENCES sub_administrative_areas(country_code, administrative_area_id, id) NOT VALID;
+
+CREATE INDEX idx_administrative_areas_state ON administrative_areas(country_code, state_id);
+CREATE INDEX idx_sub_administrative_areas_area ON sub_administrative_areas(country_code, administrative_area_id);
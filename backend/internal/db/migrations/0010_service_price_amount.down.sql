@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_services_price_amount_id;
+DROP INDEX IF EXISTS idx_services_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_services_created_at ON services(created_at);
+
+ALTER TABLE services DROP COLUMN IF EXISTS price_amount;
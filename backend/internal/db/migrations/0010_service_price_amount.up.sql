@@ -0,0 +1,16 @@
+-- price is free text such as "500 BDT / hour". price_amount holds its first
+-- number so searches can sort by price; the application keeps it in sync.
+ALTER TABLE services ADD COLUMN price_amount NUMERIC(12,2);
+
+UPDATE services s
+SET price_amount = p.amount::NUMERIC(12,2)
+FROM (
+	SELECT id, replace(substring(price FROM '[0-9][0-9,]*(?:\.[0-9]+)?'), ',', '') AS amount
+	FROM services
+) p
+WHERE p.id = s.id AND p.amount ~ '^[0-9]{1,10}(\.[0-9]+)?$';
+
+-- Keyset pagination orders by each sort key with id as the tie-breaker
+CREATE INDEX idx_services_created_at_id ON services(created_at DESC, id DESC);
+CREATE INDEX idx_services_price_amount_id ON services(price_amount, id);
+DROP INDEX IF EXISTS idx_services_created_at;
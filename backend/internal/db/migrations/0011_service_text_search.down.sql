@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_services_search_skeleton;
+DROP INDEX IF EXISTS idx_services_search_document;
+
+ALTER TABLE services DROP COLUMN IF EXISTS search_skeleton;
+ALTER TABLE services DROP COLUMN IF EXISTS search_document;
@@ -0,0 +1,19 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Weighted full-text document. The simple configuration does no stemming, so
+-- Bangla and English words are indexed the same way.
+ALTER TABLE services ADD COLUMN search_document TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', title), 'A') ||
+	setweight(to_tsvector('simple', caption), 'B') ||
+	setweight(to_tsvector('simple', area), 'B') ||
+	setweight(to_tsvector('simple', description), 'C') ||
+	setweight(jsonb_to_tsvector('simple', COALESCE(features, '{}'), '["string"]'), 'D')
+) STORED;
+
+-- Romanised, spelling-folded copy of the same fields for typo-tolerant
+-- trigram matching. The application writes it; NULL rows are filled in
+-- after migrating up, by cmd/migrate or by the API when MIGRATE_ON_BOOT is set.
+ALTER TABLE services ADD COLUMN search_skeleton TEXT;
+
+CREATE INDEX idx_services_search_document ON services USING GIN(search_document);
+CREATE INDEX idx_services_search_skeleton ON services USING GIN(search_skeleton gin_trgm_ops);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_services_coordinates;
+
+ALTER TABLE services
+	DROP CONSTRAINT IF EXISTS chk_services_coordinates,
+	DROP COLUMN IF EXISTS latitude,
+	DROP COLUMN IF EXISTS longitude;
+
+ALTER TABLE sub_administrative_areas
+	DROP CONSTRAINT IF EXISTS chk_sub_administrative_areas_coordinates,
+	DROP COLUMN IF EXISTS latitude,
+	DROP COLUMN IF EXISTS longitude;
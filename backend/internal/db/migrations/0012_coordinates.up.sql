@@ -0,0 +1,22 @@
+-- Optional WGS 84 coordinates. A service without its own falls back to the
+-- centroid of its sub-administrative area in nearby searches.
+ALTER TABLE sub_administrative_areas
+	ADD COLUMN latitude DOUBLE PRECISION,
+	ADD COLUMN longitude DOUBLE PRECISION,
+	ADD CONSTRAINT chk_sub_administrative_areas_coordinates CHECK (
+		(latitude IS NULL) = (longitude IS NULL)
+		AND latitude BETWEEN -90 AND 90
+		AND longitude BETWEEN -180 AND 180
+	);
+
+ALTER TABLE services
+	ADD COLUMN latitude DOUBLE PRECISION,
+	ADD COLUMN longitude DOUBLE PRECISION,
+	ADD CONSTRAINT chk_services_coordinates CHECK (
+		(latitude IS NULL) = (longitude IS NULL)
+		AND latitude BETWEEN -90 AND 90
+		AND longitude BETWEEN -180 AND 180
+	);
+
+-- Nearby searches narrow to a bounding box before computing distances
+CREATE INDEX idx_services_coordinates ON services(latitude, longitude) WHERE latitude IS NOT NULL;
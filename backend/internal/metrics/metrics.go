@@ -0,0 +1,181 @@
+// Package metrics defines the Prometheus metrics the API exposes on /metrics.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bhinno"
+
+// Registry holds every metric served by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"route", "method", "status"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Login attempts by method (email, google, phone) and result (success, failure, error).",
+	}, []string{"method", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		logins,
+	)
+}
+
+var handler = promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return handler
+}
+
+// ObserveRequest records one finished request. route is the matched mux
+// pattern, empty when nothing matched.
+func ObserveRequest(route, method string, status int, d time.Duration) {
+	if route == "" {
+		// Keeps scanners probing random paths from creating a series each
+		route = "unmatched"
+	}
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpDuration.WithLabelValues(route, method, code).Observe(d.Seconds())
+}
+
+// ObserveLogin records a login attempt from the status it was answered with.
+func ObserveLogin(method string, status int) {
+	result := "success"
+	switch {
+	case status >= 500:
+		result = "error"
+	case status >= 400:
+		result = "failure"
+	}
+	logins.WithLabelValues(method, result).Inc()
+}
+
+// RegisterPool exports the connection statistics of pool.
+func RegisterPool(pool *pgxpool.Pool) {
+	Registry.MustRegister(&poolCollector{pool: pool})
+}
+
+// RegisterStores exports business gauges read from stores at scrape time.
+func RegisterStores(stores models.Stores) {
+	Registry.MustRegister(&storeCollector{stores: stores})
+}
+
+var (
+	poolAcquired     = poolDesc("acquired_conns", "Connections currently checked out of the pool.")
+	poolIdle         = poolDesc("idle_conns", "Idle connections in the pool.")
+	poolConstructing = poolDesc("constructing_conns", "Connections being established.")
+	poolTotal        = poolDesc("total_conns", "Connections in the pool, in any state.")
+	poolMax          = poolDesc("max_conns", "Configured maximum pool size.")
+	poolAcquires     = poolDesc("acquires_total", "Successful connection acquisitions.")
+	poolAcquireTime  = poolDesc("acquire_duration_seconds_total", "Total time spent acquiring connections.")
+	poolWaited       = poolDesc("waited_acquires_total", "Acquisitions that had to wait because no connection was idle.")
+	poolWaitTime     = poolDesc("waited_acquire_duration_seconds_total", "Total time spent waiting for a connection when none was idle.")
+	poolCanceled     = poolDesc("canceled_acquires_total", "Acquisitions cancelled by their context.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{poolAcquired, poolIdle, poolConstructing, poolTotal, poolMax, poolAcquires, poolAcquireTime, poolWaited, poolWaitTime, poolCanceled} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(poolAcquired, float64(s.AcquiredConns()))
+	gauge(poolIdle, float64(s.IdleConns()))
+	gauge(poolConstructing, float64(s.ConstructingConns()))
+	gauge(poolTotal, float64(s.TotalConns()))
+	gauge(poolMax, float64(s.MaxConns()))
+	counter(poolAcquires, float64(s.AcquireCount()))
+	counter(poolAcquireTime, s.AcquireDuration().Seconds())
+	counter(poolWaited, float64(s.EmptyAcquireCount()))
+	counter(poolWaitTime, s.EmptyAcquireWaitTime().Seconds())
+	counter(poolCanceled, float64(s.CanceledAcquireCount()))
+}
+
+var (
+	activeServices = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "active_services"),
+		"Active services of non-blocked providers, by country code.", []string{"country"}, nil)
+	bookings = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "bookings"),
+		"Bookings by status.", []string{"status"}, nil)
+)
+
+type storeCollector struct {
+	stores models.Stores
+}
+
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeServices
+	ch <- bookings
+}
+
+// Collect queries the stores on every scrape; a failed query leaves its
+// gauge out of that scrape rather than failing the whole endpoint.
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if counts, err := c.stores.Services.CountActiveByCountry(ctx); err != nil {
+		slog.Error("cannot count active services for metrics", "error", err)
+	} else {
+		for country, n := range counts {
+			ch <- prometheus.MustNewConstMetric(activeServices, prometheus.GaugeValue, float64(n), country)
+		}
+	}
+
+	if counts, err := c.stores.Bookings.CountByStatus(ctx); err != nil {
+		slog.Error("cannot count bookings for metrics", "error", err)
+	} else {
+		for status, n := range counts {
+			ch <- prometheus.MustNewConstMetric(bookings, prometheus.GaugeValue, float64(n), status)
+		}
+	}
+}
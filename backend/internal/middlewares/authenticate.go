@@ -10,9 +10,11 @@ import (
 const (
 	CtxUserID         string = "userID"
 	CtxRole           string = "role"
+	CtxSessionID      string = "sessionID"
 	CtxRoleSuperAdmin string = "superadmin"
 	CtxRoleAdmin      string = "admin"
 	CtxRoleClient     string = "client"
+	CtxRoleProvider   string = "provider"
 )
 
 func Authenticate(next http.HandlerFunc) http.HandlerFunc {
@@ -26,15 +28,17 @@ func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 
 		accessToken := strings.TrimSpace(authHeader[7:])
 
-		userID, role, err := utils.VerifyJWT(accessToken)
+		claims, err := utils.VerifyJWT(accessToken)
 		if err != nil {
 			utils.JSON(w, http.StatusUnauthorized, false, "Unauthorized", nil)
 			return
 		}
 
 		ctx := r.Context()
-		ctx = context.WithValue(ctx, CtxUserID, userID)
-		ctx = context.WithValue(ctx, CtxRole, role)
+		setLogUserID(ctx, claims.UserID)
+		ctx = context.WithValue(ctx, CtxUserID, claims.UserID)
+		ctx = context.WithValue(ctx, CtxRole, claims.Role)
+		ctx = context.WithValue(ctx, CtxSessionID, claims.SessionID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
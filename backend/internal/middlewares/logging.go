@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"backend/internal/utils"
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const CtxRequestID string = "requestID"
+
+// ctxRequestLog holds the *requestLog that inner middlewares fill in for the access log
+const ctxRequestLog string = "requestLog"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID keeps a well-formed X-Request-ID from the client or assigns a new
+// one, and echoes it in the response so both sides can quote it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID.MatchString(id) {
+			id, _ = utils.GenerateToken(12)
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CtxRequestID, id)))
+	})
+}
+
+// RequestIDFrom returns the request ID assigned by RequestID, or "".
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(CtxRequestID).(string)
+	return id
+}
+
+// Logger returns the default logger tagged with the request ID of ctx.
+func Logger(ctx context.Context) *slog.Logger {
+	if id := RequestIDFrom(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
+
+type requestLog struct {
+	userID int64
+}
+
+// setLogUserID records the authenticated user for the access log line.
+func setLogUserID(ctx context.Context, userID int64) {
+	if l, ok := ctx.Value(ctxRequestLog).(*requestLog); ok {
+		l.userID = userID
+	}
+}
+
+// LogRequests writes one access log line per request once it completes. It
+// must wrap the mux, or middlewares that pass the request on unchanged, so
+// the matched route pattern is known afterwards.
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info := &requestLog{}
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		// The mux records the matched pattern on the request it is handed
+		inner := r.WithContext(context.WithValue(r.Context(), ctxRequestLog, info))
+		next.ServeHTTP(rec, inner)
+
+		level := slog.LevelInfo
+		if rec.status >= 500 {
+			level = slog.LevelError
+		}
+
+		Logger(r.Context()).LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", inner.Pattern),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("user_id", info.userID),
+			slog.Int64("bytes", rec.bytes),
+		)
+	})
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status, rec.wroteHeader = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
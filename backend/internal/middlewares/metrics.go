@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"backend/internal/metrics"
+	"net/http"
+	"time"
+)
+
+// Instrument records request counts and latency per route pattern. Like
+// LogRequests it must wrap the mux directly.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		metrics.ObserveRequest(r.Pattern, r.Method, rec.status, time.Since(start))
+	})
+}
+
+// CountLogins records the outcome of a login endpoint for the given method.
+func CountLogins(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(rec, r)
+
+		metrics.ObserveLogin(method, rec.status)
+	}
+}
@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"backend/internal/utils"
+	"net/http"
+	"slices"
+)
+
+type Permission string
+
+const (
+	PermProfileRead   Permission = "profile:read"
+	PermSessionManage Permission = "session:manage"
+	PermBookingCreate Permission = "booking:create"
+	PermBookingRead   Permission = "booking:read"
+	PermBookingUpdate Permission = "booking:update"
+	PermRatingWrite   Permission = "rating:write"
+	PermServiceWrite  Permission = "service:write"
+	// Turn one's own client account into a provider account
+	PermProviderOnboard Permission = "provider:onboard"
+	PermCategoryWrite   Permission = "category:write"
+	PermLocationWrite   Permission = "location:write"
+	// Read any booking, not only one's own
+	PermBookingReadAny Permission = "booking:read_any"
+	// Delete any rating, not only one's own
+	PermRatingModerate Permission = "rating:moderate"
+	PermUserManage     Permission = "user:manage"
+	PermAuditRead      Permission = "audit:read"
+	// Grant or revoke the admin role
+	PermUserPromoteAdmin Permission = "user:promote_admin"
+)
+
+var clientPermissions = []Permission{
+	PermProfileRead,
+	PermSessionManage,
+	PermBookingCreate,
+	PermBookingRead,
+	PermBookingUpdate,
+	PermRatingWrite,
+	PermProviderOnboard,
+}
+
+// Sign-ups start as clients and become providers through
+// POST /api/auth/me/provider, or when an admin sets the role.
+var providerPermissions = slices.Concat(clientPermissions, []Permission{
+	PermServiceWrite,
+})
+
+var adminPermissions = slices.Concat(providerPermissions, []Permission{
+	PermCategoryWrite,
+	PermLocationWrite,
+	PermBookingReadAny,
+	PermRatingModerate,
+	PermUserManage,
+	PermAuditRead,
+})
+
+var superAdminPermissions = slices.Concat(adminPermissions, []Permission{
+	PermUserPromoteAdmin,
+})
+
+// rolePermissions is the whole authorization policy. A role missing from it
+// has no permissions at all.
+var rolePermissions = map[string]map[Permission]bool{
+	CtxRoleClient:     permissionSet(clientPermissions),
+	CtxRoleProvider:   permissionSet(providerPermissions),
+	CtxRoleAdmin:      permissionSet(adminPermissions),
+	CtxRoleSuperAdmin: permissionSet(superAdminPermissions),
+}
+
+func permissionSet(perms []Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+// KnownRole reports whether role appears in the permission matrix.
+func KnownRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role string, perm Permission) bool {
+	return rolePermissions[role][perm]
+}
+
+// Can reports whether the authenticated caller of r holds perm.
+func Can(r *http.Request, perm Permission) bool {
+	role, _ := r.Context().Value(CtxRole).(string)
+	return HasPermission(role, perm)
+}
+
+// RequirePermission lets the request through only when the authenticated role
+// grants perm. It must run after Authenticate.
+func RequirePermission(perm Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !Can(r, perm) {
+			utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AuditEvent records who changed what. Diff maps each changed field to its
+// {"before", "after"} values.
+type AuditEvent struct {
+	ID         int64          `json:"id"`
+	ActorID    int64          `json:"actor_id,omitempty"`
+	ActorRole  string         `json:"actor_role,omitempty"`
+	Action     string         `json:"action"`
+	TargetType string         `json:"target_type"`
+	TargetID   string         `json:"target_id"`
+	Diff       map[string]any `json:"diff,omitempty"`
+	IP         string         `json:"ip,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// AuditFilter narrows an audit log query; zero values are ignored.
+type AuditFilter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+const (
+	BookingStatusPending   string = "pending"
+	BookingStatusConfirmed string = "confirmed"
+	BookingStatusCompleted string = "completed"
+	BookingStatusCancelled string = "cancelled"
+)
+
+var (
+	ErrInvalidBookingStatus     = errors.New("invalid booking status")
+	ErrInvalidBookingTransition = errors.New("invalid booking status transition")
+	ErrBookingStatusChanged     = errors.New("booking status changed concurrently")
+)
+
+// bookingTransitions is the booking state machine: every status maps to the
+// statuses it may move to. Completed and cancelled are terminal.
+var bookingTransitions = map[string][]string{
+	BookingStatusPending:   {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed: {BookingStatusCompleted, BookingStatusCancelled},
+	BookingStatusCompleted: {},
+	BookingStatusCancelled: {},
+}
+
+type Booking struct {
+	ID         int64     `json:"id"`
+	ServiceID  int64     `json:"service_id"`
+	UserID     int64     `json:"user_id"`
+	ProviderID int64     `json:"provider_id"`
+	Hours      string    `json:"hours,omitempty"`
+	Days       []string  `json:"days"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// hoursRange mirrors the CHECK on the hours columns, e.g. "9:00-17:30".
+var hoursRange = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):[0-5][0-9]-([01]?[0-9]|2[0-3]):[0-5][0-9]$`)
+
+// ValidHours reports whether h fits the hours columns: empty, "All day" or
+// an HH:MM-HH:MM range.
+func ValidHours(h string) bool {
+	return h == "" || h == "All day" || hoursRange.MatchString(h)
+}
+
+// ValidateBookingTransition reports whether a booking may move from one status to another.
+func ValidateBookingTransition(from, to string) error {
+	if _, ok := bookingTransitions[to]; !ok {
+		return ErrInvalidBookingStatus
+	}
+	next, ok := bookingTransitions[from]
+	if !ok {
+		return ErrInvalidBookingStatus
+	}
+	for _, s := range next {
+		if s == to {
+			return nil
+		}
+	}
+	return ErrInvalidBookingTransition
+}
@@ -0,0 +1,63 @@
+package models
+
+import "math"
+
+// EarthRadiusKM is the mean radius of the Earth.
+const EarthRadiusKM = 6371.0088
+
+// MaxNearbyRadiusKM bounds NearbyFilter.RadiusKM.
+const MaxNearbyRadiusKM = 100
+
+// NearbyFilter selects the active services of visible owners within RadiusKM
+// of a point, optionally in a category or subcategory.
+type NearbyFilter struct {
+	Latitude      float64
+	Longitude     float64
+	RadiusKM      float64
+	CategoryID    int64
+	SubcategoryID int64
+	Limit         int
+	Offset        int
+}
+
+// ValidCoordinates reports whether lat and lng are both unset, or both set
+// and on the globe.
+func ValidCoordinates(lat, lng *float64) bool {
+	if lat == nil || lng == nil {
+		return lat == nil && lng == nil
+	}
+	return *lat >= -90 && *lat <= 90 && *lng >= -180 && *lng <= 180
+}
+
+// DistanceKM is the great-circle distance between two points by the
+// haversine formula.
+func DistanceKM(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox returns a box holding every point within radiusKM of the
+// center. wraps is set when the box reaches a pole or crosses the
+// antimeridian; the longitude bounds are then meaningless and only the
+// latitude bounds apply.
+func BoundingBox(lat, lng, radiusKM float64) (minLat, maxLat, minLng, maxLng float64, wraps bool) {
+	dLat := degrees(radiusKM / EarthRadiusKM)
+	minLat, maxLat = lat-dLat, lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return max(minLat, -90), min(maxLat, 90), -180, 180, true
+	}
+
+	// Widest where the box's edge is nearest a pole
+	dLng := degrees(math.Asin(math.Sin(radiusKM/EarthRadiusKM) / math.Cos(radians(lat))))
+	minLng, maxLng = lng-dLng, lng+dLng
+	if minLng < -180 || maxLng > 180 {
+		return minLat, maxLat, -180, 180, true
+	}
+	return minLat, maxLat, minLng, maxLng, false
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
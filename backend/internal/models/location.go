@@ -1,84 +1,102 @@
 package models
 
 import (
-	"backend/internal/db"
-	"context"
+	"errors"
+	"fmt"
 	"time"
 )
 
+var (
+	// ErrInvalidLocation is returned when a row points at a state or area
+	// that does not exist in its country.
+	ErrInvalidLocation = errors.New("unknown location")
+	// ErrLocationInUse is returned when removing a country, state or area
+	// that services still point at.
+	ErrLocationInUse = errors.New("location is used by services")
+)
+
+// Location is a country. Its hierarchy is only filled in when fetched by code.
 type Location struct {
-	CountryCode            string                 `json:"country_code"`
-	CountryName            string                 `json:"country_name"`
-	CountryFlag            string                 `json:"country_flag"`
-	States                 map[string]interface{} `json:"states,omitempty"`
-	AdministrativeAreas    map[string]interface{} `json:"administrative_areas,omitempty"`
-	SubAdministrativeAreas map[string]interface{} `json:"sub_administrative_areas,omitempty"`
-	CreatedAt              time.Time              `json:"created_at"`
+	CountryCode            string                   `json:"country_code"`
+	CountryName            string                   `json:"country_name"`
+	CountryFlag            string                   `json:"country_flag"`
+	States                 []*State                 `json:"states,omitempty"`
+	AdministrativeAreas    []*AdministrativeArea    `json:"administrative_areas,omitempty"`
+	SubAdministrativeAreas []*SubAdministrativeArea `json:"sub_administrative_areas,omitempty"`
+	CreatedAt              time.Time                `json:"created_at"`
+}
+
+// State is the first level below a country, a division in Bangladesh. IDs
+// are only unique within a country.
+type State struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// AdministrativeArea is the level below a state, a district in Bangladesh.
+type AdministrativeArea struct {
+	ID      int    `json:"id"`
+	StateID int    `json:"state_id"`
+	Name    string `json:"name"`
+}
+
+// SubAdministrativeArea is the level below an area, an upazila in Bangladesh.
+// Its optional centroid places services that have no coordinates of their own.
+type SubAdministrativeArea struct {
+	ID                   int      `json:"id"`
+	AdministrativeAreaID int      `json:"administrative_area_id"`
+	Name                 string   `json:"name"`
+	Latitude             *float64 `json:"latitude,omitempty"`
+	Longitude            *float64 `json:"longitude,omitempty"`
 }
 
-// Get all countries (for users, without JSON fields)
-func GetAllCountries(ctx context.Context) ([]*Location, error) {
-	rows, err := db.Pool.Query(ctx, `
-		SELECT country_code, country_name, country_flag
-		FROM locations
-		ORDER BY country_name
-	`)
-	if err != nil {
-		return nil, err
+// Validate checks that the country is named, that every id is positive and
+// unique within its level, that every area points at an existing parent and
+// that centroids are valid coordinates.
+func (l *Location) Validate() error {
+	if l.CountryCode == "" || l.CountryName == "" {
+		return errors.New("country code and name are required")
 	}
-	defer rows.Close()
 
-	var locations []*Location
-	for rows.Next() {
-		loc := &Location{}
-		if err := rows.Scan(&loc.CountryCode, &loc.CountryName, &loc.CountryFlag); err != nil {
-			return nil, err
+	states := make(map[int]bool, len(l.States))
+	for _, s := range l.States {
+		if err := checkPlace("state", s.ID, s.Name, states); err != nil {
+			return err
 		}
-		locations = append(locations, loc)
 	}
-	return locations, nil
-}
 
-// Get full country by code
-func GetLocationByCode(ctx context.Context, code string) (*Location, error) {
-	loc := &Location{}
-	err := db.Pool.QueryRow(ctx, `
-		SELECT country_code, country_name, country_flag, states, administrative_areas, sub_administrative_areas, created_at
-		FROM locations
-		WHERE country_code=$1
-	`, code).Scan(
-		&loc.CountryCode, &loc.CountryName, &loc.CountryFlag,
-		&loc.States, &loc.AdministrativeAreas, &loc.SubAdministrativeAreas,
-		&loc.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
+	areas := make(map[int]bool, len(l.AdministrativeAreas))
+	for _, a := range l.AdministrativeAreas {
+		if err := checkPlace("administrative area", a.ID, a.Name, areas); err != nil {
+			return err
+		}
+		if !states[a.StateID] {
+			return fmt.Errorf("administrative area %d points at unknown state %d", a.ID, a.StateID)
+		}
 	}
-	return loc, nil
-}
 
-// Admin: create location
-func CreateLocation(ctx context.Context, loc *Location) error {
-	_, err := db.Pool.Exec(ctx, `
-		INSERT INTO locations
-		(country_code, country_name, country_flag, states, administrative_areas, sub_administrative_areas)
-		VALUES ($1,$2,$3,$4,$5,$6)
-	`, loc.CountryCode, loc.CountryName, loc.CountryFlag, loc.States, loc.AdministrativeAreas, loc.SubAdministrativeAreas)
-	return err
-}
-
-// Admin: update location
-func UpdateLocation(ctx context.Context, loc *Location) error {
-	_, err := db.Pool.Exec(ctx, `
-		UPDATE locations
-		SET country_name=$1, country_flag=$2, states=$3, administrative_areas=$4, sub_administrative_areas=$5
-		WHERE country_code=$6
-	`, loc.CountryName, loc.CountryFlag, loc.States, loc.AdministrativeAreas, loc.SubAdministrativeAreas, loc.CountryCode)
-	return err
+	subAreas := make(map[int]bool, len(l.SubAdministrativeAreas))
+	for _, a := range l.SubAdministrativeAreas {
+		if err := checkPlace("sub-administrative area", a.ID, a.Name, subAreas); err != nil {
+			return err
+		}
+		if !areas[a.AdministrativeAreaID] {
+			return fmt.Errorf("sub-administrative area %d points at unknown administrative area %d", a.ID, a.AdministrativeAreaID)
+		}
+		if !ValidCoordinates(a.Latitude, a.Longitude) {
+			return fmt.Errorf("sub-administrative area %d needs both latitude and longitude, within range", a.ID)
+		}
+	}
+	return nil
 }
 
-// Admin: delete location
-func DeleteLocation(ctx context.Context, code string) error {
-	_, err := db.Pool.Exec(ctx, `DELETE FROM locations WHERE country_code=$1`, code)
-	return err
+func checkPlace(level string, id int, name string, seen map[int]bool) error {
+	if id <= 0 || name == "" {
+		return fmt.Errorf("every %s needs a positive id and a name", level)
+	}
+	if seen[id] {
+		return fmt.Errorf("duplicate %s id %d", level, id)
+	}
+	seen[id] = true
+	return nil
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrOTPMismatch = errors.New("otp does not match")
+	ErrOTPLocked   = errors.New("too many otp attempts")
+)
+
+// PhoneOTP is the pending one-time login code for a phone number. Only the
+// hash of the code is stored. Sends and Failures count codes and wrong
+// guesses since WindowStart, across resends.
+type PhoneOTP struct {
+	Phone       string
+	CodeHash    string
+	Attempts    int
+	Sends       int
+	Failures    int
+	WindowStart time.Time
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+// OTPLimits caps the guesses at one code (Attempts), and the codes sent
+// (Sends) and wrong guesses (Failures) per phone over a rolling Window.
+type OTPLimits struct {
+	Attempts int
+	Sends    int
+	Failures int
+	Window   time.Duration
+}
+
+// WindowOver reports whether the rolling window of otp has ended by now.
+func (l OTPLimits) WindowOver(otp *PhoneOTP, now time.Time) bool {
+	return !otp.WindowStart.Add(l.Window).After(now)
+}
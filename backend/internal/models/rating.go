@@ -0,0 +1,19 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRatingExists = errors.New("booking already rated")
+
+type Rating struct {
+	ID         int64     `json:"id"`
+	BookingID  int64     `json:"booking_id"`
+	UserID     int64     `json:"user_id"`
+	ProviderID int64     `json:"provider_id"`
+	ServiceID  int64     `json:"service_id"`
+	Rating     float64   `json:"rating"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
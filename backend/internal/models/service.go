@@ -1,8 +1,13 @@
 package models
 
 import (
-	"backend/internal/db"
-	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,6 +26,7 @@ type Service struct {
 	Caption                 string                 `json:"caption"`
 	Description             string                 `json:"description"`
 	Price                   string                 `json:"price"`
+	PriceAmount             *float64               `json:"price_amount,omitempty"`
 	Features                map[string]interface{} `json:"features,omitempty"`
 	Hours                   string                 `json:"hours,omitempty"`
 	Days                    []string               `json:"days"`
@@ -28,151 +34,184 @@ type Service struct {
 	PageLink                string                 `json:"page_link,omitempty"`
 	MessengerName           string                 `json:"messenger_name,omitempty"`
 	MessengerLink           string                 `json:"messenger_link,omitempty"`
+	Latitude                *float64               `json:"latitude,omitempty"`
+	Longitude               *float64               `json:"longitude,omitempty"`
 	CreatedAt               time.Time              `json:"created_at"`
-}
 
-func CreateService(ctx context.Context, s *Service) error {
-	_, err := db.Pool.Exec(ctx, `
-		INSERT INTO services (
-			active, user_id, country_code, category_id, subcategory_id,
-			state_id, administrative_area_id, sub_administrative_area_id,
-			area, title, caption, description, price,
-			features, hours, days,
-			page_name, page_link, messenger_name, messenger_link
-		) VALUES (
-			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20
-		)
-	`, s.Active, s.UserID, s.CountryCode, s.CategoryID, s.SubcategoryID,
-		s.StateID, s.AdministrativeAreaID, s.SubAdministrativeAreaID,
-		s.Area, s.Title, s.Caption, s.Description, s.Price,
-		s.Features, s.Hours, s.Days,
-		s.PageName, s.PageLink, s.MessengerName, s.MessengerLink,
-	)
-	return err
+	// Set by a search with a query: how well the service matches and an
+	// HTML-escaped excerpt with the matched words wrapped in <mark>
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+	// Set by a nearby search: kilometres from the searched point to the
+	// service, or to its sub-area's centroid when it has no coordinates
+	DistanceKM *float64 `json:"distance_km,omitempty"`
 }
 
-func GetServiceByID(ctx context.Context, id int64) (*Service, error) {
-	s := &Service{}
-	err := db.Pool.QueryRow(ctx, `
-		SELECT
-			id, active, user_id, country_code, category_id, subcategory_id,
-			state_id, administrative_area_id, sub_administrative_area_id,
-			area, title, caption, description, price,
-			features, hours, days,
-			page_name, page_link, messenger_name, messenger_link,
-			created_at
-		FROM services
-		WHERE id=$1
-	`, id).Scan(
-		&s.ID, &s.Active, &s.UserID, &s.CountryCode, &s.CategoryID, &s.SubcategoryID,
-		&s.StateID, &s.AdministrativeAreaID, &s.SubAdministrativeAreaID,
-		&s.Area, &s.Title, &s.Caption, &s.Description, &s.Price,
-		&s.Features, &s.Hours, &s.Days,
-		&s.PageName, &s.PageLink, &s.MessengerName, &s.MessengerLink,
-		&s.CreatedAt,
-	)
+var firstNumber = regexp.MustCompile(`[0-9][0-9,]*(?:\.[0-9]+)?`)
+
+// ParsePrice returns the first number in a free-form price such as
+// "1,200 BDT / hour", rounded to cents, or nil when there is none or it does
+// not fit the price_amount column.
+func ParsePrice(price string) *float64 {
+	amount := strings.ReplaceAll(firstNumber.FindString(price), ",", "")
+	whole, _, _ := strings.Cut(amount, ".")
+	if whole == "" || len(whole) > 10 {
+		return nil
+	}
+	v, err := strconv.ParseFloat(amount, 64)
 	if err != nil {
-		return nil, err
+		return nil
 	}
-	return s, nil
+	v = math.Round(v*100) / 100
+	return &v
 }
 
-func GetServicesByFilters(ctx context.Context, country string, stateID, adminID, subadminID int, categoryID, subcategoryID int64) ([]*Service, error) {
-	rows, err := db.Pool.Query(ctx, `
-		SELECT 
-			id, active, user_id, country_code, category_id, subcategory_id,
-			state_id, administrative_area_id, sub_administrative_area_id,
-			area, title, caption, description, price,
-			features, hours, days,
-			page_name, page_link, messenger_name, messenger_link,
-			created_at
-		FROM services
-		WHERE country_code=$1 AND state_id=$2 AND administrative_area_id=$3 AND sub_administrative_area_id=$4
-		  AND category_id=$5 AND subcategory_id=$6
-		  AND active=TRUE
-		ORDER BY created_at DESC
-	`, country, stateID, adminID, subadminID, categoryID, subcategoryID)
-	if err != nil {
-		return nil, err
+// Orders of a service search.
+const (
+	ServiceSortNewest = "newest"
+	// ServiceSortRating puts the best rated providers first
+	ServiceSortRating = "rating"
+	// ServiceSortPrice puts the cheapest first and unpriced services last
+	ServiceSortPrice = "price"
+	// ServiceSortRelevance puts the best matches of the query first
+	ServiceSortRelevance = "relevance"
+)
+
+// ValidServiceSort reports whether sort is one of the search orders.
+func ValidServiceSort(sort string) bool {
+	switch sort {
+	case ServiceSortNewest, ServiceSortRating, ServiceSortPrice, ServiceSortRelevance:
+		return true
 	}
-	defer rows.Close()
-
-	var services []*Service
-	for rows.Next() {
-		s := &Service{}
-		if err := rows.Scan(
-			&s.ID, &s.Active, &s.UserID, &s.CountryCode, &s.CategoryID, &s.SubcategoryID,
-			&s.StateID, &s.AdministrativeAreaID, &s.SubAdministrativeAreaID,
-			&s.Area, &s.Title, &s.Caption, &s.Description, &s.Price,
-			&s.Features, &s.Hours, &s.Days,
-			&s.PageName, &s.PageLink, &s.MessengerName, &s.MessengerLink,
-			&s.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		services = append(services, s)
+	return false
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ServiceFilter narrows a search of active services; zero values are
+// ignored. Days matches services open on every listed day. Query is matched
+// as words against the title, caption, area, description and feature values,
+// and also loosely, to tolerate typos and romanised Bangla.
+type ServiceFilter struct {
+	CountryCode             string
+	StateID                 int
+	AdministrativeAreaID    int
+	SubAdministrativeAreaID int
+	CategoryID              int64
+	SubcategoryID           int64
+	Days                    []string
+	Query                   string
+	Sort                    string
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	Limit  int
+}
+
+// ServicePage is one page of a search. Total counts every match, not only
+// those after the cursor; NextCursor is empty on the last page.
+type ServicePage struct {
+	Services   []*Service     `json:"services"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Facets     *ServiceFacets `json:"facets,omitempty"`
+}
+
+// Facets of a service search.
+const (
+	ServiceFacetCategories          = "categories"
+	ServiceFacetSubcategories       = "subcategories"
+	ServiceFacetStates              = "states"
+	ServiceFacetAdministrativeAreas = "administrative_areas"
+	ServiceFacetDays                = "days"
+)
+
+// ServiceFacets counts the services matching a search per option of each
+// filter, keyed by id or weekday; options without services are left out.
+// Each facet is counted under every filter but its own, so the options next
+// to the chosen one keep their counts. States and areas are only counted
+// within a country, as their ids are only unique there.
+type ServiceFacets struct {
+	Categories          map[string]int `json:"categories"`
+	Subcategories       map[string]int `json:"subcategories"`
+	States              map[string]int `json:"states,omitempty"`
+	AdministrativeAreas map[string]int `json:"administrative_areas,omitempty"`
+	Days                map[string]int `json:"days"`
+}
+
+// ForFacet returns the filter facet is counted under: f without the facet's
+// own filter or the filters below it, a category's subcategory or a state's
+// areas.
+func (f ServiceFilter) ForFacet(facet string) ServiceFilter {
+	switch facet {
+	case ServiceFacetCategories:
+		f.CategoryID, f.SubcategoryID = 0, 0
+	case ServiceFacetSubcategories:
+		f.SubcategoryID = 0
+	case ServiceFacetStates:
+		f.StateID, f.AdministrativeAreaID, f.SubAdministrativeAreaID = 0, 0, 0
+	case ServiceFacetAdministrativeAreas:
+		f.AdministrativeAreaID, f.SubAdministrativeAreaID = 0, 0
+	case ServiceFacetDays:
+		f.Days = nil
 	}
+	return f
+}
 
-	return services, nil
+// ServiceCursor is the position after the last service of a page: its sort
+// key and id. Key is empty for an unpriced service in a price search.
+type ServiceCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k,omitempty"`
+	ID   int64  `json:"id"`
 }
 
-func UpdateService(ctx context.Context, s *Service) error {
-	_, err := db.Pool.Exec(ctx, `
-		UPDATE services
-		SET active=$1, country_code=$2, category_id=$3, subcategory_id=$4,
-		    state_id=$5, administrative_area_id=$6, sub_administrative_area_id=$7,
-		    area=$8, title=$9, caption=$10, description=$11,
-		    price=$12, features=$13, hours=$14, days=$15,
-		    page_name=$16, page_link=$17, messenger_name=$18, messenger_link=$19
-		WHERE id=$20
-	`, s.Active, s.CountryCode, s.CategoryID, s.SubcategoryID,
-		s.StateID, s.AdministrativeAreaID, s.SubAdministrativeAreaID,
-		s.Area, s.Title, s.Caption, s.Description,
-		s.Price, s.Features, s.Hours, s.Days,
-		s.PageName, s.PageLink, s.MessengerName, s.MessengerLink,
-		s.ID,
-	)
-	return err
+// NewServiceCursor returns the cursor after s in a search ordered by sort.
+// rating is the average rating of the service's provider.
+func NewServiceCursor(sort string, s *Service, rating float64) ServiceCursor {
+	c := ServiceCursor{Sort: sort, ID: s.ID}
+	switch sort {
+	case ServiceSortRating:
+		c.Key = strconv.FormatFloat(rating, 'f', -1, 64)
+	case ServiceSortPrice:
+		if s.PriceAmount != nil {
+			c.Key = strconv.FormatFloat(*s.PriceAmount, 'f', -1, 64)
+		}
+	case ServiceSortRelevance:
+		c.Key = strconv.FormatFloat(s.Rank, 'f', -1, 64)
+	default:
+		c.Key = s.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
 }
 
-func DeleteService(ctx context.Context, id int64) error {
-	_, err := db.Pool.Exec(ctx, `DELETE FROM services WHERE id=$1`, id)
-	return err
+func (c ServiceCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
 
-func ListServices(ctx context.Context) ([]*Service, error) {
-	rows, err := db.Pool.Query(ctx, `
-		SELECT 
-			id, active, user_id, country_code, category_id, subcategory_id,
-			state_id, administrative_area_id, sub_administrative_area_id,
-			area, title, caption, description, price,
-			features, hours, days,
-			page_name, page_link, messenger_name, messenger_link,
-			created_at
-		FROM services
-		WHERE active=TRUE
-		ORDER BY created_at DESC
-	`)
+// DecodeServiceCursor parses a cursor issued for a search ordered by sort.
+func DecodeServiceCursor(s, sort string) (*ServiceCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidCursor
 	}
-	defer rows.Close()
-
-	var services []*Service
-	for rows.Next() {
-		s := &Service{}
-		if err := rows.Scan(
-			&s.ID, &s.Active, &s.UserID, &s.CountryCode, &s.CategoryID, &s.SubcategoryID,
-			&s.StateID, &s.AdministrativeAreaID, &s.SubAdministrativeAreaID,
-			&s.Area, &s.Title, &s.Caption, &s.Description, &s.Price,
-			&s.Features, &s.Hours, &s.Days,
-			&s.PageName, &s.PageLink, &s.MessengerName, &s.MessengerLink,
-			&s.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		services = append(services, s)
+	c := &ServiceCursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.Sort != sort || c.ID <= 0 {
+		return nil, ErrInvalidCursor
 	}
 
-	return services, nil
+	switch sort {
+	case ServiceSortNewest:
+		_, err = time.Parse(time.RFC3339Nano, c.Key)
+	case ServiceSortRating, ServiceSortRelevance:
+		_, err = strconv.ParseFloat(c.Key, 64)
+	case ServiceSortPrice:
+		if c.Key != "" {
+			_, err = strconv.ParseFloat(c.Key, 64)
+		}
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
 }
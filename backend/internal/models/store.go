@@ -0,0 +1,199 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by every store when the requested row does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Stores groups every store the HTTP handlers depend on. internal/store/postgres
+// backs it with pgx, internal/store/memory with plain maps for tests and local
+// experiments.
+type Stores struct {
+	Users         UserStore
+	Categories    CategoryStore
+	SubCategories SubCategoryStore
+	Locations     LocationStore
+	Services      ServiceStore
+	Bookings      BookingStore
+	Ratings       RatingStore
+	OTPs          OTPStore
+	Sessions      SessionStore
+	Audit         AuditStore
+}
+
+type UserStore interface {
+	// EnsureSuperAdmin creates the superadmin or resets its credentials.
+	EnsureSuperAdmin(ctx context.Context, email, hashedPassword string) error
+	CreateWithGoogle(ctx context.Context, u *User) error
+	// CreateWithEmail inserts an unverified user and sets u.ID.
+	CreateWithEmail(ctx context.Context, u *User) error
+	// CreateWithPhone inserts a user whose phone has already been verified and sets u.ID.
+	CreateWithPhone(ctx context.Context, u *User) error
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByGoogleID(ctx context.Context, googleID string) (*User, error)
+	GetByPhone(ctx context.Context, phone string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	Update(ctx context.Context, u *User) error
+	// LinkGoogle stores u.GoogleID and u.Avatar and marks u verified. An
+	// unverified account was never proven to belong to the email's owner, so
+	// its password, pending verification and sessions are dropped.
+	LinkGoogle(ctx context.Context, u *User) error
+	// Search returns one page of users matching f, newest first, and the
+	// total number of matches.
+	Search(ctx context.Context, f UserFilter) ([]*User, int, error)
+	// SetStatus records a moderation decision on the user.
+	SetStatus(ctx context.Context, userID int64, status, reason string, changedBy int64) error
+	SetRole(ctx context.Context, userID int64, role string) error
+	// SetResetToken stores the hash of a password reset token and its expiry.
+	SetResetToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error
+	// ResetPassword consumes an unexpired reset token, sets the new password
+	// hash, marks the email verified since the token was mailed to it, and
+	// revokes all of the user's sessions. It returns ErrNotFound when
+	// the token is unknown, already used or expired.
+	ResetPassword(ctx context.Context, tokenHash, hashedPassword string) error
+	// SetVerifyToken stores the hash of an email verification token and its expiry.
+	SetVerifyToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error
+	// VerifyEmail consumes an unexpired verification token and marks the
+	// user verified. It returns ErrNotFound when the token is unknown, already
+	// used or expired.
+	VerifyEmail(ctx context.Context, tokenHash string) error
+}
+
+type SessionStore interface {
+	// Create inserts sess together with its first refresh token and sets sess.ID.
+	Create(ctx context.Context, sess *Session, tokenHash string) error
+	// Rotate exchanges a refresh token for newHash. The session keeps the
+	// expiry it was created with, so refreshing never extends it. It returns
+	// ErrNotFound when the token is unknown or its session is revoked or
+	// expired, and ErrRefreshTokenReused, after revoking the session, when the
+	// token was already rotated.
+	Rotate(ctx context.Context, tokenHash, newHash string) (*Session, error)
+	GetByID(ctx context.Context, id int64) (*Session, error)
+	// ListActive lists the user's unrevoked, unexpired sessions, most recently used first.
+	ListActive(ctx context.Context, userID int64) ([]*Session, error)
+	// Revoke ends one of the user's active sessions, or returns ErrNotFound.
+	Revoke(ctx context.Context, userID, id int64) error
+	RevokeAll(ctx context.Context, userID int64) error
+}
+
+// AuditStore is append-only: events are never updated or deleted.
+type AuditStore interface {
+	// Record inserts e and sets e.ID and e.CreatedAt.
+	Record(ctx context.Context, e *AuditEvent) error
+	// Search returns one page of events matching f, newest first, and the
+	// total number of matches.
+	Search(ctx context.Context, f AuditFilter) ([]*AuditEvent, int, error)
+}
+
+type OTPStore interface {
+	// Create stores a new code for otp.Phone, replacing any pending one. It
+	// returns ErrOTPLocked once the phone has used up its sends or failures
+	// for the current window.
+	Create(ctx context.Context, otp *PhoneOTP, limits OTPLimits) error
+	// Get returns the pending code for phone, expired or not.
+	Get(ctx context.Context, phone string) (*PhoneOTP, error)
+	// Consume checks codeHash against the pending unexpired code and deletes
+	// it on a match. A mismatch counts as an attempt and a failure and returns
+	// ErrOTPMismatch; once either limit is reached it returns ErrOTPLocked. It
+	// returns ErrNotFound when there is no pending code or it has expired.
+	Consume(ctx context.Context, phone, codeHash string, limits OTPLimits) error
+}
+
+type CategoryStore interface {
+	Create(ctx context.Context, c *Category) error
+	GetByID(ctx context.Context, id int64) (*Category, error)
+	GetAll(ctx context.Context) ([]*Category, error)
+	Update(ctx context.Context, c *Category) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type SubCategoryStore interface {
+	Create(ctx context.Context, sc *SubCategory) error
+	GetByID(ctx context.Context, id int64) (*SubCategory, error)
+	GetAll(ctx context.Context) ([]*SubCategory, error)
+	Update(ctx context.Context, sc *SubCategory) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type LocationStore interface {
+	// GetAllCountries lists countries without their hierarchy.
+	GetAllCountries(ctx context.Context) ([]*Location, error)
+	// GetByCode returns a country with its whole hierarchy, each level ordered by id.
+	GetByCode(ctx context.Context, code string) (*Location, error)
+	// Create inserts a country and its hierarchy. It returns ErrInvalidLocation
+	// when an area points at a missing parent.
+	Create(ctx context.Context, loc *Location) error
+	// Update replaces the country and its hierarchy. Removing a state or area
+	// that services use fails with ErrLocationInUse.
+	Update(ctx context.Context, loc *Location) error
+	// Delete fails with ErrLocationInUse while the country has services.
+	Delete(ctx context.Context, code string) error
+	// ListStates returns the states of a country by name, or ErrNotFound if
+	// the country does not exist.
+	ListStates(ctx context.Context, code string) ([]*State, error)
+	// ListAdministrativeAreas returns the areas of a state by name, or
+	// ErrNotFound if the state does not exist.
+	ListAdministrativeAreas(ctx context.Context, code string, stateID int) ([]*AdministrativeArea, error)
+	// ListSubAdministrativeAreas returns the sub-areas of an area by name, or
+	// ErrNotFound if the area does not exist.
+	ListSubAdministrativeAreas(ctx context.Context, code string, areaID int) ([]*SubAdministrativeArea, error)
+}
+
+type ServiceStore interface {
+	Create(ctx context.Context, s *Service) error
+	GetByID(ctx context.Context, id int64) (*Service, error)
+	// Search returns one page of active services of visible owners matching
+	// f. It returns ErrInvalidCursor when f.Cursor was not issued for f.Sort.
+	Search(ctx context.Context, f ServiceFilter) (*ServicePage, error)
+	// Facets counts the services Search would match for f per filter option.
+	// Sort, Cursor and Limit are ignored.
+	Facets(ctx context.Context, f ServiceFilter) (*ServiceFacets, error)
+	// Popularity counts active services per category and place, and lists
+	// up to titles of the most common titles.
+	Popularity(ctx context.Context, titles int) (*ServicePopularity, error)
+	// Nearby returns one page of the services within f.RadiusKM of f's
+	// point, nearest first, with DistanceKM set, and the total number of
+	// matches. A service without coordinates is placed at its sub-area's
+	// centroid, and left out when that has none either.
+	Nearby(ctx context.Context, f NearbyFilter) ([]*Service, int, error)
+	Update(ctx context.Context, s *Service) error
+	Delete(ctx context.Context, id int64) error
+	// GetByUserID lists all of a user's services, inactive ones included, newest first.
+	GetByUserID(ctx context.Context, userID int64) ([]*Service, error)
+	// List returns every active service, newest first.
+	List(ctx context.Context) ([]*Service, error)
+	// CountActiveByCountry counts the services List would return, per country code.
+	CountActiveByCountry(ctx context.Context) (map[string]int, error)
+}
+
+type BookingStore interface {
+	// Create inserts a pending booking. The provider is taken from the owner
+	// of the booked service, never from the client.
+	Create(ctx context.Context, b *Booking) error
+	GetByID(ctx context.Context, id int64) (*Booking, error)
+	// GetByUserID lists bookings made by a client.
+	GetByUserID(ctx context.Context, userID int64) ([]*Booking, error)
+	// GetByProviderID lists bookings received by a provider.
+	GetByProviderID(ctx context.Context, providerID int64) ([]*Booking, error)
+	// UpdateStatus moves a booking along the state machine. It fails with
+	// ErrBookingStatusChanged if the stored status is no longer b.Status.
+	UpdateStatus(ctx context.Context, b *Booking, to string) error
+	CountByStatus(ctx context.Context) (map[string]int, error)
+}
+
+// RatingStore writes keep users.rating_avg and rating_count of the rated
+// provider in step with the ratings themselves.
+type RatingStore interface {
+	Create(ctx context.Context, rt *Rating) error
+	Update(ctx context.Context, rt *Rating) error
+	Delete(ctx context.Context, rt *Rating) error
+	GetByID(ctx context.Context, id int64) (*Rating, error)
+	GetByServiceID(ctx context.Context, serviceID int64) ([]*Rating, error)
+	GetByProviderID(ctx context.Context, providerID int64) ([]*Rating, error)
+	// GetByUserID lists ratings written by a client.
+	GetByUserID(ctx context.Context, userID int64) ([]*Rating, error)
+}
@@ -0,0 +1,72 @@
+package models
+
+// Suggestion kinds.
+const (
+	SuggestionCategory    = "category"
+	SuggestionSubcategory = "subcategory"
+	SuggestionLocation    = "location"
+	SuggestionService     = "service"
+)
+
+// Suggestion is one typeahead entry. Filter holds the /api/services query
+// parameters that select what it names, so clients can apply it as is.
+type Suggestion struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	// Context says where a location lies, e.g. "Dhaka, Bangladesh".
+	Context    string            `json:"context,omitempty"`
+	Filter     map[string]string `json:"filter"`
+	Popularity int               `json:"popularity"`
+}
+
+// ServicePopularity counts the active services of visible owners that
+// suggestions rank by. Places are keyed within their country, as their ids
+// are only unique there.
+type ServicePopularity struct {
+	Categories             map[int64]int
+	Subcategories          map[int64]int
+	Countries              map[string]int
+	States                 map[PlaceKey]int
+	AdministrativeAreas    map[PlaceKey]int
+	SubAdministrativeAreas map[PlaceKey]int
+	// Titles are the most common titles, most common first. Titles differing
+	// only in case and spacing count as one, under the variant that sorts
+	// first.
+	Titles []TitleCount
+}
+
+type PlaceKey struct {
+	CountryCode string
+	ID          int
+}
+
+type TitleCount struct {
+	Title string
+	Count int
+}
+
+// NewServicePopularity returns empty counts.
+func NewServicePopularity() *ServicePopularity {
+	return &ServicePopularity{
+		Categories:             map[int64]int{},
+		Subcategories:          map[int64]int{},
+		Countries:              map[string]int{},
+		States:                 map[PlaceKey]int{},
+		AdministrativeAreas:    map[PlaceKey]int{},
+		SubAdministrativeAreas: map[PlaceKey]int{},
+	}
+}
+
+// AddCategory counts n services in a subcategory and its category.
+func (p *ServicePopularity) AddCategory(categoryID, subcategoryID int64, n int) {
+	p.Categories[categoryID] += n
+	p.Subcategories[subcategoryID] += n
+}
+
+// AddPlace counts n services in a sub-area and the places above it.
+func (p *ServicePopularity) AddPlace(countryCode string, stateID, areaID, subAreaID, n int) {
+	p.Countries[countryCode] += n
+	p.States[PlaceKey{countryCode, stateID}] += n
+	p.AdministrativeAreas[PlaceKey{countryCode, areaID}] += n
+	p.SubAdministrativeAreas[PlaceKey{countryCode, subAreaID}] += n
+}
@@ -0,0 +1,343 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adminUser is a user as admins see it: the profile plus the moderation
+// fields that other users must not read.
+type adminUser struct {
+	*models.User
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	StatusChangedBy int64      `json:"status_changed_by,omitempty"`
+}
+
+func newAdminUser(u *models.User) adminUser {
+	return adminUser{u, u.StatusReason, u.StatusChangedAt, u.StatusChangedBy}
+}
+
+func adminUsers(users []*models.User) []adminUser {
+	out := make([]adminUser, len(users))
+	for i, u := range users {
+		out[i] = newAdminUser(u)
+	}
+	return out
+}
+
+func (s *Server) adminListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, limit, err := pageParams(q.Get("page"), q.Get("limit"))
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	f := models.UserFilter{
+		Email:  strings.TrimSpace(q.Get("email")),
+		Phone:  strings.TrimSpace(q.Get("phone")),
+		Role:   q.Get("role"),
+		Status: q.Get("status"),
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+
+	if f.Status != "" && !models.ValidUserStatus(f.Status) {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid status", nil)
+		return
+	}
+	if f.CreatedFrom, err = parseDateParam(q.Get("created_from"), false); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid created_from", nil)
+		return
+	}
+	if f.CreatedTo, err = parseDateParam(q.Get("created_to"), true); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid created_to", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	users, total, err := s.Users.Search(ctx, f)
+	if err != nil {
+		serverError(w, r, "cannot fetch users", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "users fetched successfully", map[string]any{
+		"users": adminUsers(users),
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Everything an admin needs to judge a user: the account, its services, the
+// bookings it made and received, and the ratings it wrote and received.
+func (s *Server) adminUserOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid user ID", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "user not found", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+
+	services, err := s.Services.GetByUserID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch services", err)
+		return
+	}
+	bookingsMade, err := s.Bookings.GetByUserID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch bookings", err)
+		return
+	}
+	bookingsReceived, err := s.Bookings.GetByProviderID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch bookings", err)
+		return
+	}
+	ratingsGiven, err := s.Ratings.GetByUserID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch ratings", err)
+		return
+	}
+	ratingsReceived, err := s.Ratings.GetByProviderID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch ratings", err)
+		return
+	}
+	sessions, err := s.Sessions.ListActive(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch sessions", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "user overview fetched successfully", map[string]any{
+		"user":              newAdminUser(user),
+		"services":          services,
+		"bookings_made":     bookingsMade,
+		"bookings_received": bookingsReceived,
+		"ratings_given":     ratingsGiven,
+		"ratings_received":  ratingsReceived,
+		"active_sessions":   len(sessions),
+	})
+}
+
+// Suspending or banning also ends every session of the user.
+func (s *Server) adminSetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !models.ValidUserStatus(req.Status) {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid status", nil)
+		return
+	}
+	if req.Reason == "" || len(req.Reason) > 512 {
+		utils.JSON(w, http.StatusBadRequest, false, "a reason of at most 512 characters is required", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	target, actorID, ok := s.manageableUser(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Users.SetStatus(ctx, target.ID, req.Status, req.Reason, actorID); err != nil {
+		serverError(w, r, "cannot update status", err)
+		return
+	}
+
+	before := *target
+	target.Status = req.Status
+	if target.Blocked() {
+		if err := s.Sessions.RevokeAll(ctx, target.ID); err != nil {
+			serverError(w, r, "status updated but sessions could not be revoked", err)
+			return
+		}
+	}
+
+	user, err := s.Users.GetByID(ctx, target.ID)
+	if err != nil {
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+	s.recordAudit(r, "user.status", "user", target.ID,
+		map[string]any{"status": before.Status, "status_reason": before.StatusReason},
+		map[string]any{"status": user.Status, "status_reason": user.StatusReason})
+
+	utils.JSON(w, http.StatusOK, true, "status updated successfully", map[string]any{
+		"user": newAdminUser(user),
+	})
+}
+
+// Admins move users between client and provider; granting or revoking admin
+// needs PermUserPromoteAdmin. The superadmin role is never granted or taken here.
+func (s *Server) adminSetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Role string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	switch req.Role {
+	case middlewares.CtxRoleClient, middlewares.CtxRoleProvider:
+	case middlewares.CtxRoleAdmin:
+		if !middlewares.Can(r, middlewares.PermUserPromoteAdmin) {
+			utils.JSON(w, http.StatusForbidden, false, "only the superadmin can grant the admin role", nil)
+			return
+		}
+	default:
+		utils.JSON(w, http.StatusBadRequest, false, "role must be client, provider or admin", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	target, _, ok := s.manageableUser(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Users.SetRole(ctx, target.ID, req.Role); err != nil {
+		serverError(w, r, "cannot update role", err)
+		return
+	}
+	s.recordAudit(r, "user.role", "user", target.ID, map[string]any{"role": target.Role}, map[string]any{"role": req.Role})
+	target.Role = req.Role
+
+	utils.JSON(w, http.StatusOK, true, "role updated successfully", map[string]any{
+		"user": newAdminUser(target),
+	})
+}
+
+func (s *Server) adminLogoutUserHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	target, _, ok := s.manageableUser(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Sessions.RevokeAll(ctx, target.ID); err != nil {
+		serverError(w, r, "cannot revoke sessions", err)
+		return
+	}
+	s.recordAudit(r, "user.logout", "user", target.ID, nil, nil)
+
+	utils.JSON(w, http.StatusOK, true, "user logged out from all devices", nil)
+}
+
+// manageableUser loads the user named by the {id} path value and checks that
+// the caller may act on it: nobody acts on themselves or on the superadmin,
+// and only the superadmin acts on admins. It writes the error response and
+// returns ok=false otherwise.
+func (s *Server) manageableUser(ctx context.Context, w http.ResponseWriter, r *http.Request) (target *models.User, actorID int64, ok bool) {
+	actorID, _ = r.Context().Value(middlewares.CtxUserID).(int64)
+	actorRole, _ := r.Context().Value(middlewares.CtxRole).(string)
+
+	targetID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid user ID", nil)
+		return nil, 0, false
+	}
+
+	target, err = s.Users.GetByID(ctx, targetID)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "user not found", nil)
+		return nil, 0, false
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch user", err)
+		return nil, 0, false
+	}
+
+	switch {
+	case target.ID == actorID:
+		utils.JSON(w, http.StatusForbidden, false, "cannot change your own account", nil)
+		return nil, 0, false
+	case target.Role == middlewares.CtxRoleSuperAdmin:
+		utils.JSON(w, http.StatusForbidden, false, "the superadmin cannot be changed", nil)
+		return nil, 0, false
+	case target.Role == middlewares.CtxRoleAdmin && actorRole != middlewares.CtxRoleSuperAdmin:
+		utils.JSON(w, http.StatusForbidden, false, "only the superadmin can change admins", nil)
+		return nil, 0, false
+	}
+
+	return target, actorID, true
+}
+
+// pageParams parses 1-based page and limit query values. limit defaults to 20
+// and is capped at 100; page is refused once its offset would overflow.
+func pageParams(pageStr, limitStr string) (page, limit int, err error) {
+	page, limit = 1, 20
+	if pageStr != "" {
+		if page, err = strconv.Atoi(pageStr); err != nil || page < 1 {
+			return 0, 0, errors.New("invalid page")
+		}
+	}
+	if limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 {
+			return 0, 0, errors.New("invalid limit")
+		}
+	}
+	limit = min(limit, 100)
+	// Keeps the OFFSET (page-1)*limit within what Postgres accepts
+	if page > math.MaxInt32/limit {
+		return 0, 0, errors.New("page too large")
+	}
+	return page, limit, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an upper bound means the end of that day.
+func parseDateParam(v string, upper bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
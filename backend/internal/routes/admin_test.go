@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/internal/middlewares"
+)
+
+func TestModerationIsAdminOnly(t *testing.T) {
+	api := newTestAPI(t)
+	_, admin := api.user(middlewares.CtxRoleAdmin)
+	_, client := api.user(middlewares.CtxRoleClient)
+	targetID, _ := api.user(middlewares.CtxRoleProvider)
+	path := "/api/admin/users/" + strconv.FormatInt(targetID, 10)
+
+	var data struct {
+		User struct {
+			Status          string `json:"status"`
+			StatusReason    string `json:"status_reason"`
+			StatusChangedBy int64  `json:"status_changed_by"`
+		}
+	}
+	expect(t, api.do(http.MethodPut, path+"/status", admin, map[string]any{"status": "suspended", "reason": "fake reviews"}), http.StatusOK, &data)
+	if data.User.Status != "suspended" || data.User.StatusReason != "fake reviews" || data.User.StatusChangedBy == 0 {
+		t.Fatalf("admin sees %+v", data.User)
+	}
+
+	expect(t, api.do(http.MethodGet, path, admin, nil), http.StatusOK, &data)
+	if data.User.StatusReason != "fake reviews" {
+		t.Fatalf("overview shows %+v", data.User)
+	}
+
+	w := api.do(http.MethodGet, "/api/users/"+strconv.FormatInt(targetID, 10), client, nil)
+	expect(t, w, http.StatusOK, nil)
+	if body := w.Body.String(); strings.Contains(body, "status_reason") || strings.Contains(body, "status_changed") {
+		t.Fatalf("profile leaks moderation fields: %s", body)
+	}
+}
@@ -0,0 +1,218 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var weekDays = map[string]bool{
+	"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true,
+}
+
+func validDays(days []string) bool {
+	if len(days) == 0 {
+		return false
+	}
+	for _, d := range days {
+		if !weekDays[d] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) createBookingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	var req struct {
+		ServiceID int64    `json:"service_id"`
+		Hours     string   `json:"hours"`
+		Days      []string `json:"days"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ServiceID == 0 {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	if !validDays(req.Days) {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid days", nil)
+		return
+	}
+	if !models.ValidHours(req.Hours) {
+		validationFailed(w, fieldErrors{"hours": `must be "All day" or a range like 09:00-17:30`})
+		return
+	}
+
+	service, err := s.Services.GetByID(ctx, req.ServiceID)
+	if err != nil || !service.Active {
+		utils.JSON(w, http.StatusNotFound, false, "service not found", nil)
+		return
+	}
+
+	if service.UserID == userID {
+		utils.JSON(w, http.StatusBadRequest, false, "cannot book your own service", nil)
+		return
+	}
+
+	booking := &models.Booking{
+		ServiceID: service.ID,
+		UserID:    userID,
+		Hours:     req.Hours,
+		Days:      req.Days,
+	}
+
+	if err := s.Bookings.Create(ctx, booking); err != nil {
+		serverError(w, r, "cannot create booking", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "booking created successfully", map[string]any{
+		"booking": booking,
+	})
+}
+
+// List the caller's bookings, as a client by default or as a provider with ?as=provider
+func (s *Server) getMyBookingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	var bookings []*models.Booking
+	var err error
+	switch r.URL.Query().Get("as") {
+	case "", "client":
+		bookings, err = s.Bookings.GetByUserID(ctx, userID)
+	case "provider":
+		bookings, err = s.Bookings.GetByProviderID(ctx, userID)
+	default:
+		utils.JSON(w, http.StatusBadRequest, false, "as must be client or provider", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch bookings", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "bookings fetched successfully", map[string]any{
+		"bookings": bookings,
+	})
+}
+
+func (s *Server) getBookingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	bookingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid booking ID", nil)
+		return
+	}
+
+	booking, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "booking not found", nil)
+		return
+	}
+
+	if booking.UserID != userID && booking.ProviderID != userID && !middlewares.Can(r, middlewares.PermBookingReadAny) {
+		utils.JSON(w, http.StatusForbidden, false, "cannot view someone else's booking", nil)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "booking fetched", map[string]any{
+		"booking": booking,
+	})
+}
+
+// Move a booking to a new status. The state machine decides whether the move
+// is legal at all (409 otherwise); the caller's side of the booking decides
+// whether they may make it: only the provider confirms or completes, either
+// party may cancel.
+func (s *Server) updateBookingStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	bookingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid booking ID", nil)
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Status == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	booking, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "booking not found", nil)
+		return
+	}
+
+	isClient := booking.UserID == userID
+	isProvider := booking.ProviderID == userID
+	if !isClient && !isProvider {
+		utils.JSON(w, http.StatusForbidden, false, "cannot update someone else's booking", nil)
+		return
+	}
+
+	switch err := models.ValidateBookingTransition(booking.Status, req.Status); {
+	case errors.Is(err, models.ErrInvalidBookingStatus):
+		utils.JSON(w, http.StatusBadRequest, false, "invalid booking status", nil)
+		return
+	case errors.Is(err, models.ErrInvalidBookingTransition):
+		utils.JSON(w, http.StatusConflict, false, "cannot move booking from "+booking.Status+" to "+req.Status, nil)
+		return
+	}
+
+	switch req.Status {
+	case models.BookingStatusConfirmed, models.BookingStatusCompleted:
+		if !isProvider {
+			utils.JSON(w, http.StatusForbidden, false, "only the provider can mark a booking as "+req.Status, nil)
+			return
+		}
+	}
+
+	if err := s.Bookings.UpdateStatus(ctx, booking, req.Status); err != nil {
+		if errors.Is(err, models.ErrBookingStatusChanged) {
+			utils.JSON(w, http.StatusConflict, false, "booking was updated by someone else, please retry", nil)
+			return
+		}
+		serverError(w, r, "cannot update booking", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "booking updated successfully", map[string]any{
+		"booking": booking,
+	})
+}
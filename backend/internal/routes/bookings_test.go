@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/middlewares"
+	"backend/internal/models"
+)
+
+func TestBookNewService(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	clientID, client := api.user(middlewares.CtxRoleClient)
+	svc := api.createService(provider, c.service("Plumber"))
+
+	var data struct{ Booking *models.Booking }
+	expect(t, api.do(http.MethodPost, "/api/bookings", client, map[string]any{
+		"service_id": svc.ID,
+		"hours":      "09:00-12:30",
+		"days":       []string{"sat"},
+	}), http.StatusOK, &data)
+	if data.Booking.ServiceID != svc.ID || data.Booking.UserID != clientID {
+		t.Fatalf("booking = %+v", data.Booking)
+	}
+
+	expect(t, api.do(http.MethodPost, "/api/bookings", provider, map[string]any{
+		"service_id": svc.ID,
+		"hours":      "All day",
+		"days":       []string{"sat"},
+	}), http.StatusBadRequest, nil)
+}
+
+func TestBookingHoursMustMatchColumnRule(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	_, client := api.user(middlewares.CtxRoleClient)
+	svc := api.createService(provider, c.service("Plumber"))
+
+	for _, hours := range []string{"9-5", "09:00-25:00", "morning"} {
+		w := api.do(http.MethodPost, "/api/bookings", client, map[string]any{
+			"service_id": svc.ID,
+			"hours":      hours,
+			"days":       []string{"mon"},
+		})
+		expect(t, w, http.StatusUnprocessableEntity, nil)
+	}
+}
@@ -1,23 +1,14 @@
 package routes
 
 import (
-	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/utils"
-	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
 )
 
-func createCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) createCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	type Request struct {
 		Name        string `json:"name"`
 		Description string `json:"description"`
@@ -29,29 +20,23 @@ func createCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	cat := &models.Category{
 		Name:        req.Name,
 		Description: req.Description,
 	}
 
-	if err := models.CreateCategory(ctx, cat); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot create category", nil)
+	if err := s.Categories.Create(ctx, cat); err != nil {
+		serverError(w, r, "cannot create category", err)
 		return
 	}
+	s.recordAudit(r, "category.create", "category", cat.ID, nil, cat)
 
 	utils.JSON(w, http.StatusOK, true, "category created", cat)
 }
 
-func updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
 
 	type Request struct {
@@ -65,59 +50,65 @@ func updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
+
+	before, err := s.Categories.GetByID(ctx, id)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "category not found", nil)
+		return
+	}
 
 	cat := &models.Category{
 		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
+		CreatedAt:   before.CreatedAt,
 	}
 
-	if err := models.UpdateCategory(ctx, cat); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot update category", nil)
+	if err := s.Categories.Update(ctx, cat); err != nil {
+		serverError(w, r, "cannot update category", err)
 		return
 	}
+	s.recordAudit(r, "category.update", "category", id, before, cat)
 
 	utils.JSON(w, http.StatusOK, true, "category updated", cat)
 }
 
-func deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, _ := strconv.ParseInt(idStr, 10, 64)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
+
+	before, err := s.Categories.GetByID(ctx, id)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "category not found", nil)
+		return
+	}
 
-	if err := models.DeleteCategory(ctx, id); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot delete category", nil)
+	if err := s.Categories.Delete(ctx, id); err != nil {
+		serverError(w, r, "cannot delete category", err)
 		return
 	}
+	s.recordAudit(r, "category.delete", "category", id, before, nil)
 
 	utils.JSON(w, http.StatusOK, true, "category deleted", nil)
 }
 
 //
 
-func getCategoriesAndSubcategoriesHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) getCategoriesAndSubcategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	cats, err := models.GetAllCategories(ctx)
+	cats, err := s.Categories.GetAll(ctx)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch categories", nil)
+		serverError(w, r, "cannot fetch categories", err)
 		return
 	}
 
-	scats, err := models.GetAllSubCategories(ctx)
+	scats, err := s.SubCategories.GetAll(ctx)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch sub-categories", nil)
+		serverError(w, r, "cannot fetch sub-categories", err)
 		return
 	}
 
@@ -129,13 +120,7 @@ func getCategoriesAndSubcategoriesHandler(w http.ResponseWriter, r *http.Request
 
 //
 
-func createSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) createSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	type Request struct {
 		CategoryID  int64  `json:"category_id"`
 		Name        string `json:"name"`
@@ -148,8 +133,7 @@ func createSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	sc := &models.SubCategory{
 		CategoryID:  req.CategoryID,
@@ -157,21 +141,16 @@ func createSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		Description: req.Description,
 	}
 
-	if err := models.CreateSubCategory(ctx, sc); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot create subcategory", nil)
+	if err := s.SubCategories.Create(ctx, sc); err != nil {
+		serverError(w, r, "cannot create subcategory", err)
 		return
 	}
+	s.recordAudit(r, "subcategory.create", "subcategory", sc.ID, nil, sc)
 
 	utils.JSON(w, http.StatusOK, true, "subcategory created", sc)
 }
 
-func updateSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) updateSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
 
 	type Request struct {
@@ -186,41 +165,48 @@ func updateSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
+
+	before, err := s.SubCategories.GetByID(ctx, id)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "subcategory not found", nil)
+		return
+	}
 
 	sc := &models.SubCategory{
 		ID:          id,
 		CategoryID:  req.CategoryID,
 		Name:        req.Name,
 		Description: req.Description,
+		CreatedAt:   before.CreatedAt,
 	}
 
-	if err := models.UpdateSubCategory(ctx, sc); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot update subcategory", nil)
+	if err := s.SubCategories.Update(ctx, sc); err != nil {
+		serverError(w, r, "cannot update subcategory", err)
 		return
 	}
+	s.recordAudit(r, "subcategory.update", "subcategory", id, before, sc)
 
 	utils.JSON(w, http.StatusOK, true, "subcategory updated", sc)
 }
 
-func deleteSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	role := r.Context().Value(middlewares.CtxRole).(string)
-	if role == "client" {
-		utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
-		return
-	}
-
+func (s *Server) deleteSubCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, _ := strconv.ParseInt(idStr, 10, 64)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
+
+	before, err := s.SubCategories.GetByID(ctx, id)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "subcategory not found", nil)
+		return
+	}
 
-	if err := models.DeleteSubCategory(ctx, id); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot delete subcategory", nil)
+	if err := s.SubCategories.Delete(ctx, id); err != nil {
+		serverError(w, r, "cannot delete subcategory", err)
 		return
 	}
+	s.recordAudit(r, "subcategory.delete", "subcategory", id, before, nil)
 
 	utils.JSON(w, http.StatusOK, true, "subcategory deleted", nil)
 }
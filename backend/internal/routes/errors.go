@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/utils"
+	"net/http"
+)
+
+// serverError logs err under the request ID and answers 500 with msg, so the
+// client never sees the underlying error but the logs can be searched for it.
+func serverError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	middlewares.Logger(r.Context()).Error(msg, "error", err, "route", r.Pattern)
+	utils.JSON(w, http.StatusInternalServerError, false, msg, nil)
+}
+
+// fieldErrors maps request fields, by their JSON name, to what is wrong with them.
+type fieldErrors map[string]string
+
+// validationFailed answers 422 with one message per invalid field.
+func validationFailed(w http.ResponseWriter, errs fieldErrors) {
+	utils.JSON(w, http.StatusUnprocessableEntity, false, "validation failed", map[string]any{
+		"errors": errs,
+	})
+}
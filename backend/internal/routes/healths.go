@@ -1,11 +1,26 @@
 package routes
 
 import (
+	"crypto/subtle"
 	"net/http"
 
+	"backend/internal/metrics"
 	"backend/internal/utils"
 )
 
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	utils.JSON(w, http.StatusOK, true, "Service is healthy", map[string]string{"status": "ok"})
 }
+
+// Serves Prometheus metrics, behind METRICS_TOKEN when one is configured.
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.MetricsToken != "" {
+		got := []byte(r.Header.Get("Authorization"))
+		want := []byte("Bearer " + s.cfg.MetricsToken)
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			utils.JSON(w, http.StatusUnauthorized, false, "Unauthorized", nil)
+			return
+		}
+	}
+	metrics.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"backend/internal/utils"
+	"encoding/json"
+	"net/http"
+)
+
+// Publishes the token verification keys. JWK Set consumers expect the bare
+// document, so it is not wrapped in the usual response envelope.
+func (s *Server) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(utils.JWKS())
+}
@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// backgroundTimeout bounds work a handler leaves running after it responds.
+const backgroundTimeout = 30 * time.Second
+
+// BaseContext is meant for http.Server.BaseContext so that Shutdown can
+// cancel requests still in flight.
+func (s *Server) BaseContext(net.Listener) context.Context {
+	return s.ctx
+}
+
+// track runs h under the route's timeout and counts it as in flight.
+func (s *Server) track(pattern string, h http.HandlerFunc) http.HandlerFunc {
+	timeout := time.Duration(s.cfg.RequestTimeout) * time.Second
+	if sec, ok := s.cfg.RouteTimeouts[pattern]; ok {
+		timeout = time.Duration(sec) * time.Second
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.tasks.Add(1)
+		defer s.tasks.Done()
+
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		h(w, r)
+	}
+}
+
+// background runs fn after the handler returns. fn keeps the request's values
+// but not its cancellation, and is cancelled by Shutdown instead.
+func (s *Server) background(ctx context.Context, fn func(ctx context.Context)) {
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundTimeout)
+		defer cancel()
+		stop := context.AfterFunc(s.ctx, cancel)
+		defer stop()
+
+		fn(ctx)
+	}()
+}
+
+// Shutdown waits for running handlers and background tasks until ctx is done,
+// then cancels the rest and waits for them to return. Call it once
+// http.Server.Shutdown has stopped new requests from arriving.
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.tasks.Wait()
+		close(done)
+	}()
+	defer s.cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
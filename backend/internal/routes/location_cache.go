@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"backend/internal/utils"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// locationCacheTTL bounds how long a body may outlive a change made
+// elsewhere, by another instance or the seed command. Changes made through
+// this server drop the cache at once.
+const locationCacheTTL = 5 * time.Minute
+
+// maxCachedLocations caps the cache; the bundled data has a few hundred
+// navigable places, so only abuse reaches it.
+const maxCachedLocations = 2048
+
+// locationKey names one location response: a level of the hierarchy, the
+// country and the parsed id of the parent it lists.
+type locationKey struct {
+	level string
+	code  string
+	id    int
+}
+
+// locationCache keeps the encoded responses of the public location routes,
+// so revalidating with If-None-Match costs no queries.
+type locationCache struct {
+	mu      sync.Mutex
+	entries map[locationKey]cachedLocation
+	// generation counts resets; a body loaded before one is not stored
+	generation int
+}
+
+type cachedLocation struct {
+	body    *utils.CachedJSON
+	expires time.Time
+}
+
+// serve answers r from the cache when it can. Otherwise it returns false and
+// the generation to pass to respond once the data is loaded.
+func (c *locationCache) serve(w http.ResponseWriter, r *http.Request, key locationKey) (generation int, served bool) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	generation = c.generation
+	c.mu.Unlock()
+
+	if !ok || time.Now().After(e.expires) {
+		return generation, false
+	}
+	e.body.Write(w, r)
+	return generation, true
+}
+
+// respond encodes data, writes it like utils.JSONCached and keeps it unless
+// the cache was reset since generation or is full of live entries.
+func (c *locationCache) respond(w http.ResponseWriter, r *http.Request, key locationKey, generation int, message string, data any) {
+	body, err := utils.NewCachedJSON(message, data)
+	if err != nil {
+		serverError(w, r, "cannot encode response", err)
+		return
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.store(key, body)
+	}
+	c.mu.Unlock()
+
+	body.Write(w, r)
+}
+
+// store keeps body under key, dropping expired entries first when the cache
+// is full. The caller holds c.mu.
+func (c *locationCache) store(key locationKey, body *utils.CachedJSON) {
+	if c.entries == nil {
+		c.entries = map[locationKey]cachedLocation{}
+	}
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCachedLocations {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCachedLocations {
+			return
+		}
+	}
+	c.entries[key] = cachedLocation{body: body, expires: now.Add(locationCacheTTL)}
+}
+
+// reset drops every cached body; call it after any location write.
+func (c *locationCache) reset() {
+	c.mu.Lock()
+	c.entries = nil
+	c.generation++
+	c.mu.Unlock()
+}
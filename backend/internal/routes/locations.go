@@ -1,35 +1,38 @@
 package routes
 
 import (
-	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/utils"
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"time"
+	"strconv"
 )
 
-// List all countries (for users) – without JSON fields
-func getCountriesHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// List all countries (for users) – without their hierarchy
+func (s *Server) getCountriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	countries, err := models.GetAllCountries(ctx)
+	key := locationKey{level: "countries"}
+	generation, served := s.locationCache.serve(w, r, key)
+	if served {
+		return
+	}
+
+	countries, err := s.Locations.GetAllCountries(ctx)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch countries", nil)
+		serverError(w, r, "cannot fetch countries", err)
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, true, "countries fetched", map[string]any{
+	s.locationCache.respond(w, r, key, generation, "countries fetched", map[string]any{
 		"countries": countries,
 	})
 }
 
 // Get full country details by code
-func getCountryHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) getCountryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	code := r.PathValue("code")
 	if code == "" {
@@ -37,54 +40,152 @@ func getCountryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	country, err := models.GetLocationByCode(ctx, code)
-	if err != nil {
+	key := locationKey{level: "country", code: code}
+	generation, served := s.locationCache.serve(w, r, key)
+	if served {
+		return
+	}
+
+	country, err := s.Locations.GetByCode(ctx, code)
+	if errors.Is(err, models.ErrNotFound) {
 		utils.JSON(w, http.StatusNotFound, false, "country not found", nil)
 		return
 	}
+	if err != nil {
+		serverError(w, r, "cannot fetch country", err)
+		return
+	}
 
-	utils.JSON(w, http.StatusOK, true, "country fetched", map[string]any{
+	s.locationCache.respond(w, r, key, generation, "country fetched", map[string]any{
 		"country": country,
 	})
 }
 
-// Admin: create a new country
-func createLocationHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// List the states of a country
+func (s *Server) getStatesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	userRole, ok := r.Context().Value(middlewares.CtxRole).(string)
-	if !ok || (userRole != middlewares.CtxRoleSuperAdmin && userRole != middlewares.CtxRoleAdmin) {
-		utils.JSON(w, http.StatusForbidden, false, "admin access required", nil)
+	code := r.PathValue("code")
+	key := locationKey{level: "states", code: code}
+	generation, served := s.locationCache.serve(w, r, key)
+	if served {
+		return
+	}
+
+	states, err := s.Locations.ListStates(ctx, code)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "country not found", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch states", err)
+		return
+	}
+
+	s.locationCache.respond(w, r, key, generation, "states fetched", map[string]any{
+		"states": states,
+	})
+}
+
+// List the administrative areas of a state
+func (s *Server) getAdministrativeAreasHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	stateID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid state ID", nil)
+		return
+	}
+
+	code := r.PathValue("code")
+	key := locationKey{level: "areas", code: code, id: stateID}
+	generation, served := s.locationCache.serve(w, r, key)
+	if served {
+		return
+	}
+
+	areas, err := s.Locations.ListAdministrativeAreas(ctx, code, stateID)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "state not found", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch administrative areas", err)
+		return
+	}
+
+	s.locationCache.respond(w, r, key, generation, "administrative areas fetched", map[string]any{
+		"administrative_areas": areas,
+	})
+}
+
+// List the sub-administrative areas of an administrative area
+func (s *Server) getSubAdministrativeAreasHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	areaID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid administrative area ID", nil)
 		return
 	}
 
+	code := r.PathValue("code")
+	key := locationKey{level: "sub-areas", code: code, id: areaID}
+	generation, served := s.locationCache.serve(w, r, key)
+	if served {
+		return
+	}
+
+	subAreas, err := s.Locations.ListSubAdministrativeAreas(ctx, code, areaID)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "administrative area not found", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot fetch sub-administrative areas", err)
+		return
+	}
+
+	s.locationCache.respond(w, r, key, generation, "sub-administrative areas fetched", map[string]any{
+		"sub_administrative_areas": subAreas,
+	})
+}
+
+// Admin: create a new country
+func (s *Server) createLocationHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req models.Location
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
 		return
 	}
 
-	if err := models.CreateLocation(ctx, &req); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot create country", nil)
+	if err := req.Validate(); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
 
+	err := s.Locations.Create(ctx, &req)
+	if errors.Is(err, models.ErrInvalidLocation) {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot create country", err)
+		return
+	}
+	s.locationCache.reset()
+	s.recordAudit(r, "location.create", "location", req.CountryCode, nil, req)
+
 	utils.JSON(w, http.StatusOK, true, "country created", map[string]any{
 		"country": req,
 	})
 }
 
 // Admin: update country
-func updateLocationHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	userRole, ok := r.Context().Value(middlewares.CtxRole).(string)
-	if !ok || (userRole != middlewares.CtxRoleSuperAdmin && userRole != middlewares.CtxRoleAdmin) {
-		utils.JSON(w, http.StatusForbidden, false, "admin access required", nil)
-		return
-	}
+func (s *Server) updateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	code := r.PathValue("code")
 	if code == "" {
@@ -92,7 +193,7 @@ func updateLocationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	country, err := models.GetLocationByCode(ctx, code)
+	country, err := s.Locations.GetByCode(ctx, code)
 	if err != nil {
 		utils.JSON(w, http.StatusNotFound, false, "country not found", nil)
 		return
@@ -104,6 +205,8 @@ func updateLocationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := *country
+
 	// Merge fields
 	if req.CountryName != "" {
 		country.CountryName = req.CountryName
@@ -121,10 +224,26 @@ func updateLocationHandler(w http.ResponseWriter, r *http.Request) {
 		country.SubAdministrativeAreas = req.SubAdministrativeAreas
 	}
 
-	if err := models.UpdateLocation(ctx, country); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot update country", nil)
+	if err := country.Validate(); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	err = s.Locations.Update(ctx, country)
+	if errors.Is(err, models.ErrInvalidLocation) {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if errors.Is(err, models.ErrLocationInUse) {
+		utils.JSON(w, http.StatusConflict, false, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot update country", err)
 		return
 	}
+	s.locationCache.reset()
+	s.recordAudit(r, "location.update", "location", code, before, country)
 
 	utils.JSON(w, http.StatusOK, true, "country updated", map[string]any{
 		"country": country,
@@ -132,15 +251,8 @@ func updateLocationHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // Admin: delete country
-func deleteLocationHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	userRole, ok := r.Context().Value(middlewares.CtxRole).(string)
-	if !ok || (userRole != middlewares.CtxRoleSuperAdmin && userRole != middlewares.CtxRoleAdmin) {
-		utils.JSON(w, http.StatusForbidden, false, "admin access required", nil)
-		return
-	}
+func (s *Server) deleteLocationHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	code := r.PathValue("code")
 	if code == "" {
@@ -148,10 +260,23 @@ func deleteLocationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := models.DeleteLocation(ctx, code); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot delete country", nil)
+	before, err := s.Locations.GetByCode(ctx, code)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "country not found", nil)
+		return
+	}
+
+	err = s.Locations.Delete(ctx, code)
+	if errors.Is(err, models.ErrLocationInUse) {
+		utils.JSON(w, http.StatusConflict, false, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot delete country", err)
 		return
 	}
+	s.locationCache.reset()
+	s.recordAudit(r, "location.delete", "location", code, before, nil)
 
 	utils.JSON(w, http.StatusOK, true, "country deleted", nil)
 }
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/middlewares"
+	"backend/internal/utils"
+)
+
+func TestCountryRevalidation(t *testing.T) {
+	api := newTestAPI(t)
+	api.seedCatalog()
+	_, admin := api.user(middlewares.CtxRoleAdmin)
+
+	w := api.do(http.MethodGet, "/api/locations/bd", "", nil)
+	expect(t, w, http.StatusOK, nil)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+
+	w = api.do(http.MethodGet, "/api/locations/bd", "", nil, "If-None-Match", etag)
+	expect(t, w, http.StatusNotModified, nil)
+	if w.Body.Len() != 0 {
+		t.Fatalf("304 carries a body: %s", w.Body)
+	}
+
+	expect(t, api.do(http.MethodPut, "/api/locations/bd", admin, map[string]any{"country_name": "People's Republic of Bangladesh"}), http.StatusOK, nil)
+
+	var data struct {
+		Country struct {
+			CountryName string `json:"country_name"`
+		}
+	}
+	w = api.do(http.MethodGet, "/api/locations/bd", "", nil, "If-None-Match", etag)
+	expect(t, w, http.StatusOK, &data)
+	if data.Country.CountryName != "People's Republic of Bangladesh" || w.Header().Get("ETag") == etag {
+		t.Fatalf("stale country after update: %s", w.Body)
+	}
+}
+
+func TestLocationCacheKeysOnParsedIDs(t *testing.T) {
+	api := newTestAPI(t)
+	api.seedCatalog()
+
+	for _, id := range []string{"1", "01", "0001", "+1"} {
+		expect(t, api.do(http.MethodGet, "/api/locations/bd/states/"+id+"/areas", "", nil), http.StatusOK, nil)
+	}
+	expect(t, api.do(http.MethodGet, "/api/locations/bd/states/99/areas", "", nil), http.StatusNotFound, nil)
+
+	if n := len(api.server.locationCache.entries); n != 1 {
+		t.Fatalf("cache holds %d entries, want 1", n)
+	}
+}
+
+func TestLocationCacheDropsExpiredWhenFull(t *testing.T) {
+	var c locationCache
+	body, err := utils.NewCachedJSON("ok", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range maxCachedLocations {
+		c.store(locationKey{level: "areas", code: "bd", id: i}, body)
+	}
+	c.store(locationKey{level: "areas", code: "bd", id: -1}, body)
+	if _, ok := c.entries[locationKey{level: "areas", code: "bd", id: -1}]; ok {
+		t.Fatal("stored past the cap while every entry was live")
+	}
+
+	for k, e := range c.entries {
+		e.expires = time.Now().Add(-time.Second)
+		c.entries[k] = e
+	}
+	c.store(locationKey{level: "areas", code: "bd", id: -1}, body)
+	if len(c.entries) != 1 {
+		t.Fatalf("cache holds %d entries after pruning, want 1", len(c.entries))
+	}
+}
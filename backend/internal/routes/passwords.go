@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"backend/internal/mailer"
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Always answers the same way so the endpoint cannot be used to probe which
+// emails have accounts. The mail is sent in the background for the same reason.
+func (s *Server) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "email required", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	const message = "if an account exists for this email, a reset link has been sent"
+
+	email, err := utils.NormalizeEmail(req.Email)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	user, err := s.Users.GetByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, models.ErrNotFound) {
+			middlewares.Logger(r.Context()).Error("password reset lookup failed", "error", err)
+		}
+		utils.JSON(w, http.StatusOK, true, message, nil)
+		return
+	}
+
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		serverError(w, r, "cannot generate reset token", err)
+		return
+	}
+
+	ttl := time.Duration(s.cfg.PasswordResetTTL) * time.Minute
+	if err := s.Users.SetResetToken(ctx, user.ID, utils.HashToken(token), time.Now().Add(ttl)); err != nil {
+		serverError(w, r, "cannot save reset token", err)
+		return
+	}
+
+	s.sendMail(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Bhinno password",
+		Body: "Someone asked to reset the password for your Bhinno account.\n\n" +
+			"Use this link within " + strconv.Itoa(s.cfg.PasswordResetTTL) + " minutes to choose a new password:\n" +
+			tokenLink(s.cfg.PasswordResetURL, token) + "\n\n" +
+			"If this wasn't you, you can ignore this email.",
+	})
+
+	utils.JSON(w, http.StatusOK, true, message, nil)
+}
+
+func (s *Server) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "token and password required", nil)
+		return
+	}
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	err := s.Users.ResetPassword(ctx, utils.HashToken(req.Token), utils.HashPassword(req.Password))
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid or expired reset token", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot reset password", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "password reset successfully, please log in again", nil)
+}
+
+// sendMail delivers msg in the background; failures are only logged.
+func (s *Server) sendMail(ctx context.Context, msg mailer.Message) {
+	s.background(ctx, func(ctx context.Context) {
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			middlewares.Logger(ctx).Error("cannot send mail", "to", msg.To, "error", err)
+		}
+	})
+}
+
+// tokenLink appends the token to a configured client page, or returns the
+// bare token when no page is configured.
+func tokenLink(base, token string) string {
+	if base == "" {
+		return token
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
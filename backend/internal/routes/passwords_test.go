@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/utils"
+)
+
+func TestPasswordResetVerifiesEmail(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+
+	u := &models.User{Email: "legacy@example.com", Password: utils.HashPassword("Old-password1")}
+	if err := api.stores.Users.CreateWithEmail(ctx, u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := api.stores.Users.SetResetToken(ctx, u.ID, utils.HashToken("reset-token"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("set reset token: %v", err)
+	}
+
+	login := map[string]any{"email": "legacy@example.com", "password": "New-password1"}
+	expect(t, api.do(http.MethodPost, "/api/auth/password/reset", "", map[string]any{
+		"token": "reset-token", "password": "New-password1",
+	}), http.StatusOK, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/login", "", login), http.StatusOK, nil)
+}
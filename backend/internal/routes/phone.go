@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *Server) requestOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Phone string `json:"phone"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Phone == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "phone required", nil)
+		return
+	}
+
+	phone, err := utils.NormalizeBDPhone(req.Phone)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	cooldown := time.Duration(s.cfg.OTPResendPeriod) * time.Second
+	pending, err := s.OTPs.Get(ctx, phone)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		serverError(w, r, "cannot send code", err)
+		return
+	}
+	if pending != nil {
+		if wait := cooldown - time.Since(pending.CreatedAt); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			utils.JSON(w, http.StatusTooManyRequests, false, "please wait before requesting another code", nil)
+			return
+		}
+	}
+
+	code, err := utils.GenerateOTP(6)
+	if err != nil {
+		serverError(w, r, "cannot generate code", err)
+		return
+	}
+
+	otp := &models.PhoneOTP{
+		Phone:     phone,
+		CodeHash:  s.otpHash(phone, code),
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.OTPTTL) * time.Minute),
+	}
+	limits := s.otpLimits()
+	err = s.OTPs.Create(ctx, otp, limits)
+	if errors.Is(err, models.ErrOTPLocked) {
+		if pending != nil {
+			wait := time.Until(pending.WindowStart.Add(limits.Window))
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+		utils.JSON(w, http.StatusTooManyRequests, false, "too many codes requested, try again later", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot save code", err)
+		return
+	}
+
+	s.sendSMS(ctx, phone, "Your Bhinno login code is "+code+". It expires in "+strconv.Itoa(s.cfg.OTPTTL)+" minutes. Do not share it with anyone.")
+
+	utils.JSON(w, http.StatusOK, true, "code sent", map[string]any{
+		"phone":      phone,
+		"expires_in": s.cfg.OTPTTL * 60,
+	})
+}
+
+// Logs the phone's owner in, creating the account on first use.
+func (s *Server) verifyOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Phone == "" || req.Code == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "phone and code required", nil)
+		return
+	}
+
+	phone, err := utils.NormalizeBDPhone(req.Phone)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	err = s.OTPs.Consume(ctx, phone, s.otpHash(phone, req.Code), s.otpLimits())
+	switch {
+	case errors.Is(err, models.ErrOTPMismatch):
+		utils.JSON(w, http.StatusUnauthorized, false, "invalid code", nil)
+		return
+	case errors.Is(err, models.ErrOTPLocked):
+		utils.JSON(w, http.StatusTooManyRequests, false, "too many attempts, request a new code later", nil)
+		return
+	case errors.Is(err, models.ErrNotFound):
+		utils.JSON(w, http.StatusUnauthorized, false, "code expired, request a new one", nil)
+		return
+	case err != nil:
+		serverError(w, r, "cannot verify code", err)
+		return
+	}
+
+	user, err := s.Users.GetByPhone(ctx, phone)
+	if errors.Is(err, models.ErrNotFound) {
+		user = &models.User{Phone: phone, Verified: true}
+		err = s.Users.CreateWithPhone(ctx, user)
+	}
+	if err != nil {
+		serverError(w, r, "cannot log in", err)
+		return
+	}
+
+	s.respondWithTokens(ctx, w, r, user, "login successful")
+}
+
+// sendSMS delivers body in the background; failures are only logged.
+func (s *Server) sendSMS(ctx context.Context, to, body string) {
+	s.background(ctx, func(ctx context.Context) {
+		if err := s.sms.Send(ctx, to, body); err != nil {
+			middlewares.Logger(ctx).Error("cannot send sms", "to", to, "error", err)
+		}
+	})
+}
+
+func (s *Server) otpLimits() models.OTPLimits {
+	return models.OTPLimits{
+		Attempts: s.cfg.OTPMaxAttempts,
+		Sends:    s.cfg.OTPMaxSends,
+		Failures: s.cfg.OTPMaxFailures,
+		Window:   time.Duration(s.cfg.OTPLimitWindow) * time.Hour,
+	}
+}
+
+// otpHash binds the code to its phone so equal codes for different phones
+// hash differently. It is keyed because six digits are too few to survive a
+// brute force of a leaked hash.
+func (s *Server) otpHash(phone, code string) string {
+	return utils.HMACToken(s.cfg.OTPSecret, phone+":"+code)
+}
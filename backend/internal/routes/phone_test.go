@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOTPFailuresSurviveResend(t *testing.T) {
+	api := newTestAPI(t)
+	cfg := api.server.cfg
+	cfg.OTPTTL, cfg.OTPMaxAttempts, cfg.OTPLimitWindow = 5, 3, 24
+	cfg.OTPMaxSends, cfg.OTPMaxFailures = 3, 4
+
+	phone := map[string]any{"phone": "01712345678"}
+	guess := map[string]any{"phone": "01712345678", "code": "not-it"}
+
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone), http.StatusOK, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/verify-otp", "", guess), http.StatusUnauthorized, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/verify-otp", "", guess), http.StatusUnauthorized, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/verify-otp", "", guess), http.StatusTooManyRequests, nil)
+
+	// A new code resets the per-code attempts but not the failures
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone), http.StatusOK, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/verify-otp", "", guess), http.StatusTooManyRequests, nil)
+
+	w := api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone)
+	expect(t, w, http.StatusTooManyRequests, nil)
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("no Retry-After on a locked phone")
+	}
+}
+
+func TestOTPSendsAreCapped(t *testing.T) {
+	api := newTestAPI(t)
+	cfg := api.server.cfg
+	cfg.OTPTTL, cfg.OTPMaxAttempts, cfg.OTPLimitWindow = 5, 3, 24
+	cfg.OTPMaxSends, cfg.OTPMaxFailures = 2, 10
+
+	phone := map[string]any{"phone": "+8801712345678"}
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone), http.StatusOK, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone), http.StatusOK, nil)
+	expect(t, api.do(http.MethodPost, "/api/auth/phone/request-otp", "", phone), http.StatusTooManyRequests, nil)
+}
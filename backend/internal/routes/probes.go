@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds each readiness check so one hung dependency cannot
+// hold the probe past the load balancer's own timeout.
+const probeTimeout = 2 * time.Second
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// AddReadinessCheck makes /readyz report unready whenever check fails.
+// Register checks before the server starts.
+func (s *Server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	s.checks = append(s.checks, readinessCheck{name: name, check: check})
+}
+
+// Drain makes /readyz fail from now on, so load balancers stop sending
+// traffic before the listener closes.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+// locationsLoaded fails until at least one country has been seeded; without
+// them no service can be created or searched.
+func (s *Server) locationsLoaded(ctx context.Context) error {
+	countries, err := s.Locations.GetAllCountries(ctx)
+	if err != nil {
+		return err
+	}
+	if len(countries) == 0 {
+		return errors.New("no location data loaded")
+	}
+	return nil
+}
+
+// Answers 200 as long as the process can serve HTTP at all; it checks no
+// dependencies, so a database outage never gets the process restarted.
+func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, http.StatusOK, true, "alive", map[string]string{"status": "ok"})
+}
+
+type checkResult struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Runs every readiness check in parallel and answers 503 if any fails or the
+// server is draining. The probe is public, so failures are only logged.
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		utils.JSON(w, http.StatusServiceUnavailable, false, "shutting down", map[string]string{"status": "draining"})
+		return
+	}
+
+	results := make(map[string]checkResult, len(s.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(r.Context(), probeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(ctx)
+			res := checkResult{Status: "ok", DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				res.Status = "fail"
+				middlewares.Logger(ctx).Error("readiness check failed", "check", c.name, "error", err)
+			}
+
+			mu.Lock()
+			results[c.name] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		if res.Status != "ok" {
+			utils.JSON(w, http.StatusServiceUnavailable, false, "not ready", map[string]any{"checks": results})
+			return
+		}
+	}
+	utils.JSON(w, http.StatusOK, true, "ready", map[string]any{"checks": results})
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadinessHidesCheckErrors(t *testing.T) {
+	api := newTestAPI(t)
+	api.server.AddReadinessCheck("database", func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	})
+
+	w := api.do(http.MethodGet, "/readyz", "", nil)
+	var data struct {
+		Checks map[string]checkResult
+	}
+	expect(t, w, http.StatusServiceUnavailable, &data)
+	if data.Checks["database"].Status != "fail" {
+		t.Fatalf("checks = %+v", data.Checks)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.5") {
+		t.Fatalf("readiness leaks the check error: %s", w.Body)
+	}
+}
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// maxCommentLength matches the VARCHAR(1024) ratings.comment column.
+const maxCommentLength = 1024
+
+func validRating(rating float64) bool {
+	return rating >= 0 && rating <= 5
+}
+
+func validComment(comment string) bool {
+	return utf8.RuneCountInString(comment) <= maxCommentLength
+}
+
+// Clients rate a service through the completed booking they made for it
+func (s *Server) createRatingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	var req struct {
+		BookingID int64   `json:"booking_id"`
+		Rating    float64 `json:"rating"`
+		Comment   string  `json:"comment"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BookingID == 0 {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	if !validRating(req.Rating) {
+		utils.JSON(w, http.StatusBadRequest, false, "rating must be between 0 and 5", nil)
+		return
+	}
+	if !validComment(req.Comment) {
+		validationFailed(w, fieldErrors{"comment": "must be at most 1024 characters"})
+		return
+	}
+
+	booking, err := s.Bookings.GetByID(ctx, req.BookingID)
+	if err != nil || booking.UserID != userID {
+		utils.JSON(w, http.StatusNotFound, false, "booking not found", nil)
+		return
+	}
+
+	if booking.Status != models.BookingStatusCompleted {
+		utils.JSON(w, http.StatusForbidden, false, "only completed bookings can be rated", nil)
+		return
+	}
+
+	rating := &models.Rating{
+		BookingID:  booking.ID,
+		UserID:     userID,
+		ProviderID: booking.ProviderID,
+		ServiceID:  booking.ServiceID,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+	}
+
+	if err := s.Ratings.Create(ctx, rating); err != nil {
+		if errors.Is(err, models.ErrRatingExists) {
+			utils.JSON(w, http.StatusConflict, false, "booking already rated", nil)
+			return
+		}
+		serverError(w, r, "cannot create rating", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "rating created successfully", map[string]any{
+		"rating": rating,
+	})
+}
+
+func (s *Server) updateRatingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	ratingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid rating ID", nil)
+		return
+	}
+
+	rating, err := s.Ratings.GetByID(ctx, ratingID)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "rating not found", nil)
+		return
+	}
+
+	if rating.UserID != userID {
+		utils.JSON(w, http.StatusForbidden, false, "cannot edit someone else's rating", nil)
+		return
+	}
+
+	var req struct {
+		Rating  *float64 `json:"rating"`
+		Comment *string  `json:"comment"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
+	}
+
+	if req.Rating != nil {
+		if !validRating(*req.Rating) {
+			utils.JSON(w, http.StatusBadRequest, false, "rating must be between 0 and 5", nil)
+			return
+		}
+		rating.Rating = *req.Rating
+	}
+	if req.Comment != nil {
+		if !validComment(*req.Comment) {
+			validationFailed(w, fieldErrors{"comment": "must be at most 1024 characters"})
+			return
+		}
+		rating.Comment = *req.Comment
+	}
+
+	if err := s.Ratings.Update(ctx, rating); err != nil {
+		serverError(w, r, "cannot update rating", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "rating updated successfully", map[string]any{
+		"rating": rating,
+	})
+}
+
+func (s *Server) deleteRatingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	ratingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid rating ID", nil)
+		return
+	}
+
+	rating, err := s.Ratings.GetByID(ctx, ratingID)
+	if err != nil {
+		utils.JSON(w, http.StatusNotFound, false, "rating not found", nil)
+		return
+	}
+
+	if rating.UserID != userID && !middlewares.Can(r, middlewares.PermRatingModerate) {
+		utils.JSON(w, http.StatusForbidden, false, "cannot delete someone else's rating", nil)
+		return
+	}
+
+	if err := s.Ratings.Delete(ctx, rating); err != nil {
+		serverError(w, r, "cannot delete rating", err)
+		return
+	}
+	if rating.UserID != userID {
+		s.recordAudit(r, "rating.delete", "rating", rating.ID, rating, nil)
+	}
+
+	utils.JSON(w, http.StatusOK, true, "rating deleted successfully", nil)
+}
+
+func (s *Server) getServiceRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	serviceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid service ID", nil)
+		return
+	}
+
+	ratings, err := s.Ratings.GetByServiceID(ctx, serviceID)
+	if err != nil {
+		serverError(w, r, "cannot fetch ratings", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "ratings fetched successfully", map[string]any{
+		"ratings": ratings,
+	})
+}
+
+func (s *Server) getProviderRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	providerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid user ID", nil)
+		return
+	}
+
+	ratings, err := s.Ratings.GetByProviderID(ctx, providerID)
+	if err != nil {
+		serverError(w, r, "cannot fetch ratings", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "ratings fetched successfully", map[string]any{
+		"ratings": ratings,
+	})
+}
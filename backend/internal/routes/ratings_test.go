@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/internal/middlewares"
+	"backend/internal/models"
+)
+
+func TestRatingCommentLength(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	_, client := api.user(middlewares.CtxRoleClient)
+	svc := api.createService(provider, c.service("Plumber"))
+
+	var booked struct{ Booking *models.Booking }
+	expect(t, api.do(http.MethodPost, "/api/bookings", client, map[string]any{
+		"service_id": svc.ID,
+		"days":       []string{"sat"},
+	}), http.StatusOK, &booked)
+	statusPath := "/api/bookings/" + strconv.FormatInt(booked.Booking.ID, 10) + "/status"
+	for _, status := range []string{models.BookingStatusConfirmed, models.BookingStatusCompleted} {
+		expect(t, api.do(http.MethodPut, statusPath, provider, map[string]any{"status": status}), http.StatusOK, nil)
+	}
+
+	// Bangla letters are several bytes each but count as one character
+	fits, tooLong := strings.Repeat("ক", maxCommentLength), strings.Repeat("ক", maxCommentLength+1)
+
+	expect(t, api.do(http.MethodPost, "/api/ratings", client, map[string]any{
+		"booking_id": booked.Booking.ID, "rating": 4, "comment": tooLong,
+	}), http.StatusUnprocessableEntity, nil)
+
+	var rated struct{ Rating *models.Rating }
+	expect(t, api.do(http.MethodPost, "/api/ratings", client, map[string]any{
+		"booking_id": booked.Booking.ID, "rating": 4, "comment": fits,
+	}), http.StatusOK, &rated)
+
+	ratingPath := "/api/ratings/" + strconv.FormatInt(rated.Rating.ID, 10)
+	expect(t, api.do(http.MethodPut, ratingPath, client, map[string]any{"comment": tooLong}), http.StatusUnprocessableEntity, nil)
+}
@@ -1,46 +1,138 @@
 package routes
 
 import (
+	"backend/internal/config"
+	"backend/internal/mailer"
 	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/sms"
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 )
 
-func RegisterRoutes() *http.ServeMux {
+// Server carries the dependencies shared by every handler.
+type Server struct {
+	models.Stores
+	cfg    *config.Config
+	mailer mailer.Mailer
+	sms    sms.Sender
+
+	// ctx is the parent of every request and background task; Shutdown cancels it
+	ctx    context.Context
+	cancel context.CancelFunc
+	// tasks counts running handlers and background tasks
+	tasks sync.WaitGroup
+
+	checks   []readinessCheck
+	draining atomic.Bool
+
+	suggestions   search.Suggester
+	locationCache locationCache
+}
+
+func NewServer(cfg *config.Config, stores models.Stores, m mailer.Mailer, sender sms.Sender) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{Stores: stores, cfg: cfg, mailer: m, sms: sender, ctx: ctx, cancel: cancel}
+	s.AddReadinessCheck("locations", s.locationsLoaded)
+	return s
+}
+
+// RegisterRoutes wires every endpoint. Authorization policy lives here: each
+// authenticated route names the permission it needs, and the role matrix in
+// middlewares/rbac.go decides who holds it.
+func (s *Server) RegisterRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
 
+	// handle registers h with the route's timeout and tracks it for Shutdown
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, s.track(pattern, h))
+	}
+
+	// can authenticates the caller and requires perm
+	can := func(perm middlewares.Permission, h http.HandlerFunc) http.HandlerFunc {
+		return s.authenticate(middlewares.RequirePermission(perm, h))
+	}
+
 	// Healthchecks
-	mux.HandleFunc("/api/health-http", healthCheckHandler)
+	handle("/api/health-http", s.healthCheckHandler)
+	handle("GET /livez", s.livenessHandler)
+	handle("GET /readyz", s.readinessHandler)
+
+	// Prometheus scrape target
+	handle("GET /metrics", s.metricsHandler)
+
+	// Token verification keys
+	handle("GET /.well-known/jwks.json", s.jwksHandler)
 
 	// Users
-	mux.HandleFunc("POST /api/auth/google", googleAuthHandler)
-	mux.HandleFunc("POST /api/auth/email", emailAuthHandler)
-	mux.HandleFunc("POST /api/auth/refresh", refreshSessionHandler)
-	mux.HandleFunc("GET /api/auth/me", middlewares.Authenticate(getCurrentUserHandler))
-	mux.HandleFunc("POST /api/auth/logout", middlewares.Authenticate(logoutHandler))
-	mux.HandleFunc("GET /api/users/{id}", middlewares.Authenticate(getUserByIDHandler))
+	handle("POST /api/auth/google", middlewares.CountLogins("google", s.googleAuthHandler))
+	handle("POST /api/auth/register", s.registerHandler)
+	handle("POST /api/auth/login", middlewares.CountLogins("email", s.loginHandler))
+	handle("POST /api/auth/verify", s.verifyEmailHandler)
+	handle("POST /api/auth/verify/resend", s.resendVerificationHandler)
+	handle("POST /api/auth/phone/request-otp", s.requestOTPHandler)
+	handle("POST /api/auth/phone/verify-otp", middlewares.CountLogins("phone", s.verifyOTPHandler))
+	handle("POST /api/auth/refresh", s.refreshSessionHandler)
+	handle("GET /api/auth/sessions", can(middlewares.PermSessionManage, s.listSessionsHandler))
+	handle("DELETE /api/auth/sessions/{id}", can(middlewares.PermSessionManage, s.revokeSessionHandler))
+	handle("POST /api/auth/password/forgot", s.forgotPasswordHandler)
+	handle("POST /api/auth/password/reset", s.resetPasswordHandler)
+	handle("GET /api/auth/me", can(middlewares.PermProfileRead, s.getCurrentUserHandler))
+	handle("POST /api/auth/me/provider", can(middlewares.PermProviderOnboard, s.becomeProviderHandler))
+	handle("POST /api/auth/logout", can(middlewares.PermSessionManage, s.logoutHandler))
+	handle("GET /api/users/{id}", can(middlewares.PermProfileRead, s.getUserByIDHandler))
+
+	// Admin
+	handle("GET /api/admin/users", can(middlewares.PermUserManage, s.adminListUsersHandler))
+	handle("GET /api/admin/users/{id}", can(middlewares.PermUserManage, s.adminUserOverviewHandler))
+	handle("PUT /api/admin/users/{id}/status", can(middlewares.PermUserManage, s.adminSetUserStatusHandler))
+	handle("PUT /api/admin/users/{id}/role", can(middlewares.PermUserManage, s.adminSetUserRoleHandler))
+	handle("POST /api/admin/users/{id}/logout", can(middlewares.PermUserManage, s.adminLogoutUserHandler))
+	handle("GET /api/admin/audit", can(middlewares.PermAuditRead, s.getAuditEventsHandler))
 
 	// Locaations
-	mux.HandleFunc("GET /api/locations", getCountriesHandler)
-	mux.HandleFunc("GET /api/locations/{code}", getCountryHandler)
-	mux.HandleFunc("POST /api/locations", middlewares.Authenticate(createLocationHandler))
-	mux.HandleFunc("PUT /api/locations/{code}", middlewares.Authenticate(updateLocationHandler))
-	mux.HandleFunc("DELETE /api/locations/{code}", middlewares.Authenticate(deleteLocationHandler))
+	handle("GET /api/locations", s.getCountriesHandler)
+	handle("GET /api/locations/{code}", s.getCountryHandler)
+	handle("GET /api/locations/{code}/states", s.getStatesHandler)
+	handle("GET /api/locations/{code}/states/{id}/areas", s.getAdministrativeAreasHandler)
+	handle("GET /api/locations/{code}/areas/{id}/sub-areas", s.getSubAdministrativeAreasHandler)
+	handle("POST /api/locations", can(middlewares.PermLocationWrite, s.createLocationHandler))
+	handle("PUT /api/locations/{code}", can(middlewares.PermLocationWrite, s.updateLocationHandler))
+	handle("DELETE /api/locations/{code}", can(middlewares.PermLocationWrite, s.deleteLocationHandler))
 
 	// Categories & SubCategories
-	mux.HandleFunc("POST /api/categories", middlewares.Authenticate(createCategoryHandler))
-	mux.HandleFunc("PUT /api/categories/{id}", middlewares.Authenticate(updateCategoryHandler))
-	mux.HandleFunc("DELETE /api/categories/{id}", middlewares.Authenticate(deleteCategoryHandler))
-	mux.HandleFunc("POST /api/subcategories", middlewares.Authenticate(createSubCategoryHandler))
-	mux.HandleFunc("PUT /api/subcategories/{id}", middlewares.Authenticate(updateSubCategoryHandler))
-	mux.HandleFunc("DELETE /api/subcategories/{id}", middlewares.Authenticate(deleteSubCategoryHandler))
-	mux.HandleFunc("GET /api/categories-subcategories", middlewares.Authenticate(getCategoriesAndSubcategoriesHandler))
+	handle("POST /api/categories", can(middlewares.PermCategoryWrite, s.createCategoryHandler))
+	handle("PUT /api/categories/{id}", can(middlewares.PermCategoryWrite, s.updateCategoryHandler))
+	handle("DELETE /api/categories/{id}", can(middlewares.PermCategoryWrite, s.deleteCategoryHandler))
+	handle("POST /api/subcategories", can(middlewares.PermCategoryWrite, s.createSubCategoryHandler))
+	handle("PUT /api/subcategories/{id}", can(middlewares.PermCategoryWrite, s.updateSubCategoryHandler))
+	handle("DELETE /api/subcategories/{id}", can(middlewares.PermCategoryWrite, s.deleteSubCategoryHandler))
+	handle("GET /api/categories-subcategories", can(middlewares.PermProfileRead, s.getCategoriesAndSubcategoriesHandler))
 
 	// Services
-	mux.HandleFunc("POST /api/services", middlewares.Authenticate(createServiceHandler))
-	mux.HandleFunc("GET /api/services/{id}", getServiceHandler)
-	mux.HandleFunc("PUT /api/services/{id}", middlewares.Authenticate(updateServiceHandler))
-	mux.HandleFunc("DELETE /api/services/{id}", middlewares.Authenticate(deleteServiceHandler))
-	mux.HandleFunc("GET /api/services/{country_code}/{division_id}/{district_id}/{subdistrict_id}/{category_id}/{subcategory_id}", getFilteredServicesHandler)
+	handle("GET /api/services", s.searchServicesHandler)
+	handle("GET /api/services/nearby", s.nearbyServicesHandler)
+	handle("GET /api/suggest", s.suggestHandler)
+	handle("POST /api/services", can(middlewares.PermServiceWrite, s.createServiceHandler))
+	handle("GET /api/services/{id}", s.getServiceHandler)
+	handle("PUT /api/services/{id}", can(middlewares.PermServiceWrite, s.updateServiceHandler))
+	handle("DELETE /api/services/{id}", can(middlewares.PermServiceWrite, s.deleteServiceHandler))
+
+	// Bookings
+	handle("POST /api/bookings", can(middlewares.PermBookingCreate, s.createBookingHandler))
+	handle("GET /api/bookings", can(middlewares.PermBookingRead, s.getMyBookingsHandler))
+	handle("GET /api/bookings/{id}", can(middlewares.PermBookingRead, s.getBookingHandler))
+	handle("PUT /api/bookings/{id}/status", can(middlewares.PermBookingUpdate, s.updateBookingStatusHandler))
+
+	// Ratings
+	handle("POST /api/ratings", can(middlewares.PermRatingWrite, s.createRatingHandler))
+	handle("PUT /api/ratings/{id}", can(middlewares.PermRatingWrite, s.updateRatingHandler))
+	handle("DELETE /api/ratings/{id}", can(middlewares.PermRatingWrite, s.deleteRatingHandler))
+	handle("GET /api/services/{id}/ratings", s.getServiceRatingsHandler)
+	handle("GET /api/users/{id}/ratings", s.getProviderRatingsHandler)
 
 	return mux
 }
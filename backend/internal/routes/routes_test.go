@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/sms"
+	"backend/internal/store/memory"
+	"backend/internal/utils"
+)
+
+func TestMain(m *testing.M) {
+	if err := utils.InitJWT(utils.JWTOptions{Secret: "test-secret", TTLMinutes: 15}); err != nil {
+		log.Fatalf("Cannot init JWT: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// testAPI serves every route over fresh in-memory stores.
+type testAPI struct {
+	t       *testing.T
+	server  *Server
+	stores  models.Stores
+	handler http.Handler
+	users   int
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	stores := memory.New()
+	s := NewServer(&config.Config{}, stores, mailer.LogMailer{}, sms.LogSender{})
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return &testAPI{t: t, server: s, stores: stores, handler: s.RegisterRoutes()}
+}
+
+// user creates an active user with role and returns its id and an access
+// token for a live session.
+func (a *testAPI) user(role string) (int64, string) {
+	a.t.Helper()
+	ctx := context.Background()
+
+	a.users++
+	u := &models.User{Email: fmt.Sprintf("%s%d@example.com", role, a.users)}
+	if err := a.stores.Users.CreateWithEmail(ctx, u); err != nil {
+		a.t.Fatalf("create user: %v", err)
+	}
+	if err := a.stores.Users.SetRole(ctx, u.ID, role); err != nil {
+		a.t.Fatalf("set role: %v", err)
+	}
+	if err := a.stores.Users.SetStatus(ctx, u.ID, models.UserStatusActive, "", 0); err != nil {
+		a.t.Fatalf("set status: %v", err)
+	}
+
+	sess := &models.Session{UserID: u.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := a.stores.Sessions.Create(ctx, sess, fmt.Sprintf("refresh-%d", u.ID)); err != nil {
+		a.t.Fatalf("create session: %v", err)
+	}
+	token, err := utils.GenerateJWT(u.ID, role, sess.ID)
+	if err != nil {
+		a.t.Fatalf("sign token: %v", err)
+	}
+	return u.ID, token
+}
+
+// do sends body, JSON-encoded unless nil, with token as bearer if set.
+func (a *testAPI) do(method, path, token string, body any, header ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			a.t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	a.handler.ServeHTTP(w, req)
+	return w
+}
+
+// expect fails unless w has status, and decodes its data into dest if set.
+func expect(t *testing.T, w *httptest.ResponseRecorder, status int, dest any) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+	if dest == nil {
+		return
+	}
+	resp := struct{ Data json.RawMessage }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if err := json.Unmarshal(resp.Data, dest); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+}
+
+// catalog is the category and place every test service is listed under.
+type catalog struct {
+	categoryID, subcategoryID int64
+}
+
+// seedCatalog adds Bangladesh with Dhaka division, Dhaka district and two of
+// its upazilas, and a Plumbing category with a Pipe repair subcategory.
+func (a *testAPI) seedCatalog() catalog {
+	a.t.Helper()
+	ctx := context.Background()
+
+	loc := &models.Location{
+		CountryCode: "bd",
+		CountryName: "Bangladesh",
+		States:      []*models.State{{ID: 1, Name: "Dhaka"}},
+		AdministrativeAreas: []*models.AdministrativeArea{
+			{ID: 1, StateID: 1, Name: "Dhaka"},
+		},
+		SubAdministrativeAreas: []*models.SubAdministrativeArea{
+			{ID: 1, AdministrativeAreaID: 1, Name: "Mohammadpur"},
+			{ID: 2, AdministrativeAreaID: 1, Name: "Dhanmondi"},
+		},
+	}
+	if err := a.stores.Locations.Create(ctx, loc); err != nil {
+		a.t.Fatalf("create location: %v", err)
+	}
+
+	cat := &models.Category{Name: "Plumbing"}
+	if err := a.stores.Categories.Create(ctx, cat); err != nil {
+		a.t.Fatalf("create category: %v", err)
+	}
+	sub := &models.SubCategory{CategoryID: cat.ID, Name: "Pipe repair"}
+	if err := a.stores.SubCategories.Create(ctx, sub); err != nil {
+		a.t.Fatalf("create subcategory: %v", err)
+	}
+	return catalog{categoryID: cat.ID, subcategoryID: sub.ID}
+}
+
+// service is a create request for a service in c, in Mohammadpur.
+func (c catalog) service(title string) map[string]any {
+	return map[string]any{
+		"country_code":               "bd",
+		"state_id":                   1,
+		"administrative_area_id":     1,
+		"sub_administrative_area_id": 1,
+		"category_id":                c.categoryID,
+		"subcategory_id":             c.subcategoryID,
+		"title":                      title,
+		"hours":                      "09:00-17:00",
+		"days":                       []string{"sat", "sun", "mon"},
+	}
+}
+
+// createService publishes req as the provider with token and returns it.
+func (a *testAPI) createService(token string, req map[string]any) *models.Service {
+	a.t.Helper()
+	var data struct{ Service *models.Service }
+	expect(a.t, a.do(http.MethodPost, "/api/services", token, req), http.StatusOK, &data)
+	return data.Service
+}
@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// validateService checks that the subcategory belongs to the category, that
+// the state, area and sub-area form a chain in the country's hierarchy and
+// that the coordinates, if any, are valid. Once a level is invalid the levels
+// below it are not checked.
+func (s *Server) validateService(ctx context.Context, svc *models.Service) (fieldErrors, error) {
+	errs := fieldErrors{}
+	if !models.ValidCoordinates(svc.Latitude, svc.Longitude) {
+		errs["latitude"] = "latitude and longitude go together, within ±90 and ±180"
+	}
+	if err := s.validateServiceCategory(ctx, svc, errs); err != nil {
+		return nil, err
+	}
+	if err := s.validateServiceLocation(ctx, svc, errs); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+func (s *Server) validateServiceCategory(ctx context.Context, svc *models.Service, errs fieldErrors) error {
+	if svc.CategoryID == 0 {
+		errs["category_id"] = "is required"
+		return nil
+	}
+	_, err := s.Categories.GetByID(ctx, svc.CategoryID)
+	if errors.Is(err, models.ErrNotFound) {
+		errs["category_id"] = fmt.Sprintf("category %d does not exist", svc.CategoryID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if svc.SubcategoryID == 0 {
+		errs["subcategory_id"] = "is required"
+		return nil
+	}
+	sc, err := s.SubCategories.GetByID(ctx, svc.SubcategoryID)
+	if errors.Is(err, models.ErrNotFound) {
+		errs["subcategory_id"] = fmt.Sprintf("subcategory %d does not exist", svc.SubcategoryID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sc.CategoryID != svc.CategoryID {
+		errs["subcategory_id"] = fmt.Sprintf("subcategory %d is not in category %d", svc.SubcategoryID, svc.CategoryID)
+	}
+	return nil
+}
+
+func (s *Server) validateServiceLocation(ctx context.Context, svc *models.Service, errs fieldErrors) error {
+	if svc.CountryCode == "" {
+		errs["country_code"] = "is required"
+		return nil
+	}
+	states, err := s.Locations.ListStates(ctx, svc.CountryCode)
+	if errors.Is(err, models.ErrNotFound) {
+		errs["country_code"] = fmt.Sprintf("country %q does not exist", svc.CountryCode)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(states, func(st *models.State) bool { return st.ID == svc.StateID }) {
+		errs["state_id"] = fmt.Sprintf("state %d is not in country %q", svc.StateID, svc.CountryCode)
+		return nil
+	}
+
+	areas, err := s.Locations.ListAdministrativeAreas(ctx, svc.CountryCode, svc.StateID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(areas, func(a *models.AdministrativeArea) bool { return a.ID == svc.AdministrativeAreaID }) {
+		errs["administrative_area_id"] = fmt.Sprintf("administrative area %d is not in state %d", svc.AdministrativeAreaID, svc.StateID)
+		return nil
+	}
+
+	subAreas, err := s.Locations.ListSubAdministrativeAreas(ctx, svc.CountryCode, svc.AdministrativeAreaID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(subAreas, func(a *models.SubAdministrativeArea) bool { return a.ID == svc.SubAdministrativeAreaID }) {
+		errs["sub_administrative_area_id"] = fmt.Sprintf("sub-administrative area %d is not in administrative area %d", svc.SubAdministrativeAreaID, svc.AdministrativeAreaID)
+	}
+	return nil
+}
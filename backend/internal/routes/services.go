@@ -4,16 +4,16 @@ import (
 	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/utils"
-	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 )
 
-func createServiceHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) createServiceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
 	if !ok || userID == 0 {
@@ -40,6 +40,8 @@ func createServiceHandler(w http.ResponseWriter, r *http.Request) {
 		PageLink              string                 `json:"page_link"`
 		MessengerName         string                 `json:"messenger_name"`
 		MessengerLink         string                 `json:"messenger_link"`
+		Latitude              *float64               `json:"latitude"`
+		Longitude             *float64               `json:"longitude"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -47,7 +49,18 @@ func createServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	owner, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+	if owner.Status != models.UserStatusActive {
+		utils.JSON(w, http.StatusForbidden, false, "your account must be approved before you can publish services", nil)
+		return
+	}
+
 	service := &models.Service{
+		Active:                  true,
 		UserID:                  userID,
 		CountryCode:             req.CountryCode,
 		CategoryID:              req.CategoryID,
@@ -67,21 +80,38 @@ func createServiceHandler(w http.ResponseWriter, r *http.Request) {
 		PageLink:                req.PageLink,
 		MessengerName:           req.MessengerName,
 		MessengerLink:           req.MessengerLink,
+		Latitude:                req.Latitude,
+		Longitude:               req.Longitude,
+	}
+
+	errs, err := s.validateService(ctx, service)
+	if err != nil {
+		serverError(w, r, "cannot validate service", err)
+		return
+	}
+	if len(errs) > 0 {
+		validationFailed(w, errs)
+		return
 	}
 
-	if err := models.CreateService(ctx, service); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot create service", nil)
+	err = s.Services.Create(ctx, service)
+	if errors.Is(err, models.ErrInvalidLocation) {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
+	if err != nil {
+		serverError(w, r, "cannot create service", err)
+		return
+	}
+	s.recordAudit(r, "service.create", "service", service.ID, nil, service)
 
 	utils.JSON(w, http.StatusOK, true, "service created successfully", map[string]any{
 		"service": service,
 	})
 }
 
-func getServiceHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) getServiceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	idStr := r.PathValue("id")
 	serviceID, err := strconv.ParseInt(idStr, 10, 64)
@@ -90,7 +120,7 @@ func getServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service, err := models.GetServiceByID(ctx, serviceID)
+	service, err := s.Services.GetByID(ctx, serviceID)
 	if err != nil {
 		utils.JSON(w, http.StatusNotFound, false, "service not found", nil)
 		return
@@ -101,9 +131,8 @@ func getServiceHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) updateServiceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
 	if !ok || userID == 0 {
@@ -118,7 +147,7 @@ func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service, err := models.GetServiceByID(ctx, serviceID)
+	service, err := s.Services.GetByID(ctx, serviceID)
 	if err != nil {
 		utils.JSON(w, http.StatusNotFound, false, "service not found", nil)
 		return
@@ -130,6 +159,7 @@ func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
+		Active                  *bool                  `json:"active"`
 		CountryCode             *string                `json:"country_code"`
 		CategoryID              *int64                 `json:"category_id"`
 		SubcategoryID           *int64                 `json:"subcategory_id"`
@@ -148,6 +178,8 @@ func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
 		PageLink                *string                `json:"page_link"`
 		MessengerName           *string                `json:"messenger_name"`
 		MessengerLink           *string                `json:"messenger_link"`
+		Latitude                *float64               `json:"latitude"`
+		Longitude               *float64               `json:"longitude"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -155,6 +187,11 @@ func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := *service
+
+	if req.Active != nil {
+		service.Active = *req.Active
+	}
 	if req.CountryCode != nil {
 		service.CountryCode = *req.CountryCode
 	}
@@ -209,20 +246,59 @@ func updateServiceHandler(w http.ResponseWriter, r *http.Request) {
 	if req.MessengerLink != nil {
 		service.MessengerLink = *req.MessengerLink
 	}
+	if req.Latitude != nil || req.Longitude != nil {
+		service.Latitude, service.Longitude = req.Latitude, req.Longitude
+	}
+
+	// Only changed references are checked, so services saved before these
+	// checks existed stay editable
+	if referencesChanged(&before, service) {
+		errs, err := s.validateService(ctx, service)
+		if err != nil {
+			serverError(w, r, "cannot validate service", err)
+			return
+		}
+		if len(errs) > 0 {
+			validationFailed(w, errs)
+			return
+		}
+	}
 
-	if err := models.UpdateService(ctx, service); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot update service", nil)
+	err = s.Services.Update(ctx, service)
+	if errors.Is(err, models.ErrInvalidLocation) {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
+	if err != nil {
+		serverError(w, r, "cannot update service", err)
+		return
+	}
+	s.recordAudit(r, "service.update", "service", service.ID, before, service)
 
 	utils.JSON(w, http.StatusOK, true, "service updated successfully", map[string]any{
 		"service": service,
 	})
 }
 
-func deleteServiceHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// referencesChanged reports whether an update moves the service to another
+// category or location.
+func referencesChanged(before, after *models.Service) bool {
+	return before.CategoryID != after.CategoryID ||
+		before.SubcategoryID != after.SubcategoryID ||
+		before.CountryCode != after.CountryCode ||
+		before.StateID != after.StateID ||
+		before.AdministrativeAreaID != after.AdministrativeAreaID ||
+		before.SubAdministrativeAreaID != after.SubAdministrativeAreaID ||
+		!sameCoordinate(before.Latitude, after.Latitude) ||
+		!sameCoordinate(before.Longitude, after.Longitude)
+}
+
+func sameCoordinate(a, b *float64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func (s *Server) deleteServiceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
 	if !ok || userID == 0 {
@@ -237,7 +313,7 @@ func deleteServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service, err := models.GetServiceByID(ctx, serviceID)
+	service, err := s.Services.GetByID(ctx, serviceID)
 	if err != nil {
 		utils.JSON(w, http.StatusNotFound, false, "service not found", nil)
 		return
@@ -248,63 +324,180 @@ func deleteServiceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := models.DeleteService(ctx, serviceID); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot delete service", nil)
+	if err := s.Services.Delete(ctx, serviceID); err != nil {
+		serverError(w, r, "cannot delete service", err)
 		return
 	}
+	s.recordAudit(r, "service.delete", "service", serviceID, service, nil)
 
 	utils.JSON(w, http.StatusOK, true, "service deleted successfully", nil)
 }
 
-func getFilteredServicesHandler(w http.ResponseWriter, r *http.Request) {
-	country := r.PathValue("country_code")
-	stateStr := r.PathValue("state_id")
-	adminStr := r.PathValue("administrative_area_id")
-	subadminStr := r.PathValue("sub_administrative_area_id")
-	categoryStr := r.PathValue("category_id")
-	subcategoryStr := r.PathValue("subcategory_id")
+// Searches active services. Every filter is optional; state, area and
+// sub_area ids are only unique within a country, so they need country. q
+// matches English, Bangla script and romanised Bangla and defaults the sort
+// to relevance. Pages are chained with next_cursor, which is only valid for
+// the same sort. facets=true adds the counts per filter option.
+func (s *Server) searchServicesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
 
-	if country == "" || stateStr == "" || adminStr == "" || subadminStr == "" || categoryStr == "" || subcategoryStr == "" {
-		utils.JSON(w, http.StatusBadRequest, false, "all filter parameters are required", nil)
+	_, limit, err := pageParams("", q.Get("limit"))
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
 
-	stateID, err := strconv.Atoi(stateStr)
-	if err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid state_id", nil)
+	f := models.ServiceFilter{
+		CountryCode: q.Get("country"),
+		Query:       strings.TrimSpace(q.Get("q")),
+		Sort:        q.Get("sort"),
+		Cursor:      q.Get("cursor"),
+		Limit:       limit,
+	}
+
+	switch {
+	case f.Sort == "" && f.Query != "":
+		f.Sort = models.ServiceSortRelevance
+	case f.Sort == "":
+		f.Sort = models.ServiceSortNewest
+	}
+	if !models.ValidServiceSort(f.Sort) {
+		utils.JSON(w, http.StatusBadRequest, false, "sort must be newest, rating, price or relevance", nil)
 		return
 	}
-	adminID, err := strconv.Atoi(adminStr)
-	if err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid administrative_area_id", nil)
+	if f.Sort == models.ServiceSortRelevance && f.Query == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "sort relevance needs q", nil)
 		return
 	}
-	subadminID, err := strconv.Atoi(subadminStr)
-	if err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid sub_administrative_area_id", nil)
+
+	var stateID, areaID, subAreaID int64
+	for param, dest := range map[string]*int64{
+		"state":       &stateID,
+		"area":        &areaID,
+		"sub_area":    &subAreaID,
+		"category":    &f.CategoryID,
+		"subcategory": &f.SubcategoryID,
+	} {
+		if *dest, err = idParam(q.Get(param)); err != nil {
+			utils.JSON(w, http.StatusBadRequest, false, "invalid "+param, nil)
+			return
+		}
+	}
+	f.StateID, f.AdministrativeAreaID, f.SubAdministrativeAreaID = int(stateID), int(areaID), int(subAreaID)
+
+	if f.CountryCode == "" && (f.StateID != 0 || f.AdministrativeAreaID != 0 || f.SubAdministrativeAreaID != 0) {
+		utils.JSON(w, http.StatusBadRequest, false, "state, area and sub_area need country", nil)
 		return
 	}
-	categoryID, err := strconv.ParseInt(categoryStr, 10, 64)
-	if err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid category_id", nil)
+	if v := q.Get("days"); v != "" {
+		f.Days = strings.Split(v, ",")
+		if !validDays(f.Days) {
+			utils.JSON(w, http.StatusBadRequest, false, "invalid days", nil)
+			return
+		}
+	}
+	if len(f.Query) > 100 {
+		utils.JSON(w, http.StatusBadRequest, false, "q must be at most 100 characters", nil)
+		return
+	}
+	var withFacets bool
+	if v := q.Get("facets"); v != "" {
+		if withFacets, err = strconv.ParseBool(v); err != nil {
+			utils.JSON(w, http.StatusBadRequest, false, "invalid facets", nil)
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	page, err := s.Services.Search(ctx, f)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
-	subcategoryID, err := strconv.ParseInt(subcategoryStr, 10, 64)
 	if err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid subcategory_id", nil)
+		serverError(w, r, "cannot search services", err)
 		return
 	}
+	if withFacets {
+		if page.Facets, err = s.Services.Facets(ctx, f); err != nil {
+			serverError(w, r, "cannot count service facets", err)
+			return
+		}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	data := map[string]any{
+		"services":    page.Services,
+		"total":       page.Total,
+		"limit":       limit,
+		"next_cursor": page.NextCursor,
+	}
+	if withFacets {
+		data["facets"] = page.Facets
+	}
+	utils.JSON(w, http.StatusOK, true, "services fetched successfully", data)
+}
+
+// Lists the services within radius_km (default 5) of lat, lng, nearest first,
+// with their distance, a page at a time. Services without coordinates are
+// placed at their sub-area's centroid when the location data has one; the
+// bundled data has no centroids, so for now only services with their own
+// coordinates are found.
+func (s *Server) nearbyServicesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	lat, latErr := strconv.ParseFloat(q.Get("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(q.Get("lng"), 64)
+	if latErr != nil || lngErr != nil || !models.ValidCoordinates(&lat, &lng) {
+		utils.JSON(w, http.StatusBadRequest, false, "lat and lng must be valid coordinates", nil)
+		return
+	}
 
-	services, err := models.GetServicesByFilters(ctx, country, stateID, adminID, subadminID, categoryID, subcategoryID)
+	f := models.NearbyFilter{Latitude: lat, Longitude: lng, RadiusKM: 5}
+	if v := q.Get("radius_km"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 || radius > models.MaxNearbyRadiusKM {
+			utils.JSON(w, http.StatusBadRequest, false, fmt.Sprintf("radius_km must be above 0 and at most %d", models.MaxNearbyRadiusKM), nil)
+			return
+		}
+		f.RadiusKM = radius
+	}
+
+	page, limit, err := pageParams(q.Get("page"), q.Get("limit"))
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch services", nil)
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	f.Limit, f.Offset = limit, (page-1)*limit
+	for param, dest := range map[string]*int64{"category": &f.CategoryID, "subcategory": &f.SubcategoryID} {
+		if *dest, err = idParam(q.Get(param)); err != nil {
+			utils.JSON(w, http.StatusBadRequest, false, "invalid "+param, nil)
+			return
+		}
+	}
+
+	services, total, err := s.Services.Nearby(r.Context(), f)
+	if err != nil {
+		serverError(w, r, "cannot search nearby services", err)
 		return
 	}
 
 	utils.JSON(w, http.StatusOK, true, "services fetched successfully", map[string]any{
 		"services": services,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
 	})
 }
+
+// idParam parses an optional positive id, returning 0 when v is empty.
+func idParam(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err == nil && id <= 0 {
+		err = errors.New("id must be positive")
+	}
+	return id, err
+}
@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"backend/internal/middlewares"
+	"backend/internal/models"
+)
+
+type servicePage struct {
+	Services   []*models.Service
+	Total      int
+	NextCursor string `json:"next_cursor"`
+	Facets     *models.ServiceFacets
+}
+
+func TestSearchFindsCreatedServices(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+
+	plumber := api.createService(provider, c.service("Plumber in Mohammadpur"))
+	if !plumber.Active {
+		t.Fatal("new service is not active")
+	}
+	api.createService(provider, c.service("Electrician"))
+
+	var page servicePage
+	expect(t, api.do(http.MethodGet, "/api/services?q=plumber&facets=true", "", nil), http.StatusOK, &page)
+	if page.Total != 1 || len(page.Services) != 1 || page.Services[0].ID != plumber.ID {
+		t.Fatalf("search for plumber = %+v", page)
+	}
+	if page.Facets == nil || page.Facets.Categories[strconv.FormatInt(c.categoryID, 10)] != 1 {
+		t.Fatalf("facets = %+v", page.Facets)
+	}
+
+	// Typos and romanised spellings still match
+	expect(t, api.do(http.MethodGet, "/api/services?q=plumbr", "", nil), http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("search for plumbr found %d services", page.Total)
+	}
+}
+
+func TestSearchPagesWithCursor(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	for _, title := range []string{"One", "Two", "Three"} {
+		api.createService(provider, c.service(title))
+	}
+
+	var first, second servicePage
+	expect(t, api.do(http.MethodGet, "/api/services?limit=2", "", nil), http.StatusOK, &first)
+	if first.Total != 3 || len(first.Services) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	expect(t, api.do(http.MethodGet, "/api/services?limit=2&cursor="+first.NextCursor, "", nil), http.StatusOK, &second)
+	if len(second.Services) != 1 || second.NextCursor != "" || second.Services[0].Title != "One" {
+		t.Fatalf("second page = %+v", second)
+	}
+
+	expect(t, api.do(http.MethodGet, "/api/services?sort=price&cursor="+first.NextCursor, "", nil), http.StatusBadRequest, nil)
+}
+
+func TestDeactivatedServiceLeavesSearch(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	svc := api.createService(provider, c.service("Plumber"))
+
+	expect(t, api.do(http.MethodPut, "/api/services/"+strconv.FormatInt(svc.ID, 10), provider, map[string]any{"active": false}), http.StatusOK, nil)
+
+	var page servicePage
+	expect(t, api.do(http.MethodGet, "/api/services", "", nil), http.StatusOK, &page)
+	if page.Total != 0 {
+		t.Fatalf("inactive service still listed: %+v", page)
+	}
+}
+
+func TestNearbyServices(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+
+	for _, p := range []struct {
+		title    string
+		lat, lng float64
+	}{
+		{"Mohammadpur", 23.7639, 90.3589},
+		{"Dhanmondi", 23.7465, 90.3760},
+		{"Chattogram", 22.3569, 91.7832},
+	} {
+		req := c.service(p.title)
+		req["latitude"], req["longitude"] = p.lat, p.lng
+		api.createService(provider, req)
+	}
+
+	var page servicePage
+	expect(t, api.do(http.MethodGet, "/api/services/nearby?lat=23.7808&lng=90.2792&radius_km=20&limit=1", "", nil), http.StatusOK, &page)
+	if page.Total != 2 || len(page.Services) != 1 || page.Services[0].Title != "Mohammadpur" {
+		t.Fatalf("first page = %+v", page)
+	}
+	if d := page.Services[0].DistanceKM; d == nil || *d < 8 || *d > 9 {
+		t.Fatalf("distance = %v", d)
+	}
+
+	expect(t, api.do(http.MethodGet, "/api/services/nearby?lat=23.7808&lng=90.2792&radius_km=20&limit=1&page=2", "", nil), http.StatusOK, &page)
+	if page.Total != 2 || len(page.Services) != 1 || page.Services[0].Title != "Dhanmondi" {
+		t.Fatalf("second page = %+v", page)
+	}
+
+	for _, query := range []string{"lat=91&lng=0", "lat=23.78", "lat=23.78&lng=90.27&radius_km=500"} {
+		expect(t, api.do(http.MethodGet, "/api/services/nearby?"+query, "", nil), http.StatusBadRequest, nil)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.seedCatalog()
+	_, provider := api.user(middlewares.CtxRoleProvider)
+	api.createService(provider, c.service("Plumber in Dhaka"))
+	api.createService(provider, c.service("plumber  in dhaka"))
+
+	var data struct{ Suggestions []models.Suggestion }
+	expect(t, api.do(http.MethodGet, "/api/suggest?q=plu", "", nil), http.StatusOK, &data)
+	suggestions := data.Suggestions
+	if len(suggestions) != 2 {
+		t.Fatalf("suggestions = %+v", suggestions)
+	}
+	if s := suggestions[0]; s.Kind != models.SuggestionCategory || s.Popularity != 2 {
+		t.Fatalf("first suggestion = %+v", s)
+	}
+	if s := suggestions[1]; s.Kind != models.SuggestionService || s.Label != "Plumber in Dhaka" || s.Popularity != 2 {
+		t.Fatalf("second suggestion = %+v", s)
+	}
+
+	expect(t, api.do(http.MethodGet, "/api/suggest?q=mohammad", "", nil), http.StatusOK, &data)
+	suggestions = data.Suggestions
+	if len(suggestions) != 1 || suggestions[0].Filter["sub_area"] != "1" {
+		t.Fatalf("place suggestions = %+v", suggestions)
+	}
+}
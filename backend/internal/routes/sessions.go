@@ -0,0 +1,168 @@
+package routes
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// respondWithTokens starts a device session for an authenticated user and
+// writes the token pair. Clients may name the device with X-Device-Name.
+func (s *Server) respondWithTokens(ctx context.Context, w http.ResponseWriter, r *http.Request, user *models.User, message string) {
+	if user.Blocked() {
+		utils.JSON(w, http.StatusForbidden, false, "account is "+user.Status, nil)
+		return
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		serverError(w, r, "cannot generate refresh token", err)
+		return
+	}
+
+	sess := &models.Session{
+		UserID:     user.ID,
+		DeviceName: truncate(r.Header.Get("X-Device-Name"), 64),
+		UserAgent:  truncate(r.UserAgent(), 256),
+		IP:         clientIP(r),
+		ExpiresAt:  utils.RefreshTokenExpiry(),
+	}
+	if err := s.Sessions.Create(ctx, sess, utils.HashToken(refreshToken)); err != nil {
+		serverError(w, r, "cannot create session", err)
+		return
+	}
+
+	accessToken, err := utils.GenerateJWT(user.ID, user.Role, sess.ID)
+	if err != nil {
+		serverError(w, r, "cannot generate access token", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, message, map[string]any{
+		"user":          user,
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// authenticate verifies the access token and then checks that its session is
+// still open and its user is not blocked, so revoking a session or banning a
+// user takes effect immediately rather than when the token expires. The role
+// is taken from the database, not the token.
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return middlewares.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middlewares.CtxUserID).(int64)
+		sessionID, _ := r.Context().Value(middlewares.CtxSessionID).(int64)
+
+		ctx := r.Context()
+
+		sess, err := s.Sessions.GetByID(ctx, sessionID)
+		if errors.Is(err, models.ErrNotFound) || (err == nil && (sess.UserID != userID || sess.RevokedAt != nil || !sess.ExpiresAt.After(time.Now()))) {
+			utils.JSON(w, http.StatusUnauthorized, false, "Unauthorized", nil)
+			return
+		}
+		if err != nil {
+			serverError(w, r, "cannot check session", err)
+			return
+		}
+
+		user, err := s.Users.GetByID(ctx, userID)
+		if errors.Is(err, models.ErrNotFound) {
+			utils.JSON(w, http.StatusUnauthorized, false, "Unauthorized", nil)
+			return
+		}
+		if err != nil {
+			serverError(w, r, "cannot check user", err)
+			return
+		}
+		if user.Blocked() {
+			utils.JSON(w, http.StatusForbidden, false, "account is "+user.Status, nil)
+			return
+		}
+		if !middlewares.KnownRole(user.Role) {
+			utils.JSON(w, http.StatusForbidden, false, "forbidden", nil)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), middlewares.CtxRole, user.Role)))
+	})
+}
+
+func (s *Server) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+	sessionID, _ := r.Context().Value(middlewares.CtxSessionID).(int64)
+
+	ctx := r.Context()
+
+	sessions, err := s.Sessions.ListActive(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch sessions", err)
+		return
+	}
+
+	for _, sess := range sessions {
+		sess.Current = sess.ID == sessionID
+	}
+
+	utils.JSON(w, http.StatusOK, true, "sessions fetched successfully", map[string]any{
+		"sessions": sessions,
+	})
+}
+
+func (s *Server) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid session ID", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Sessions of other users are reported as missing rather than forbidden
+	err = s.Sessions.Revoke(ctx, userID, id)
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusNotFound, false, "session not found", nil)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "cannot revoke session", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, true, "session revoked successfully", nil)
+}
+
+// clientIP is the address of the direct peer; forwarding headers are not
+// trusted because the server may be exposed without a proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/utils"
+)
+
+// RefreshSuggestions builds the typeahead index now and rebuilds it every
+// interval until Shutdown. Queries arriving before the first build wait for
+// it rather than starting their own.
+func (s *Server) RefreshSuggestions(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		if err := s.suggestions.Ensure(s.ctx, s.Stores); err != nil && s.ctx.Err() == nil {
+			slog.Error("cannot build suggestions", "error", err)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.suggestions.Refresh(s.ctx, s.Stores); err != nil && s.ctx.Err() == nil {
+					slog.Error("cannot refresh suggestions", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Typeahead for the search box: categories, subcategories, places and
+// service titles matching q, each with the search filter it stands for.
+func (s *Server) suggestHandler(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" || len(q) > 100 {
+		utils.JSON(w, http.StatusBadRequest, false, "q must be 1 to 100 characters", nil)
+		return
+	}
+
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			utils.JSON(w, http.StatusBadRequest, false, "invalid limit", nil)
+			return
+		}
+		limit = min(n, 20)
+	}
+
+	if s.suggestions.Refreshed().IsZero() {
+		if err := s.suggestions.Ensure(r.Context(), s.Stores); err != nil {
+			serverError(w, r, "cannot load suggestions", err)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	utils.JSON(w, http.StatusOK, true, "suggestions fetched successfully", map[string]any{
+		"suggestions": s.suggestions.Suggest(q, limit),
+	})
+}
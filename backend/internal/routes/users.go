@@ -1,19 +1,22 @@
 package routes
 
 import (
+	"backend/internal/mailer"
 	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/auth/credentials/idtoken"
 )
 
-func googleAuthHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) googleAuthHandler(w http.ResponseWriter, r *http.Request) {
 	type GoogleAuthRequest struct {
 		IDToken     string `json:"id_token"`
 		AccessToken string `json:"access_token"`
@@ -30,8 +33,7 @@ func googleAuthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	payload, err := idtoken.Validate(ctx, req.IDToken, "YOUR_GOOGLE_CLIENT_ID_HERE")
 	if err != nil {
@@ -44,74 +46,141 @@ func googleAuthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	email, _ := payload.Claims["email"].(string)
+	// An unverified Google email proves nothing about who owns the address
+	if verified, _ := payload.Claims["email_verified"].(bool); !verified {
+		utils.JSON(w, http.StatusUnauthorized, false, "Google email is not verified", nil)
+		return
+	}
+
+	rawEmail, _ := payload.Claims["email"].(string)
 	name, _ := payload.Claims["name"].(string)
 	picture, _ := payload.Claims["picture"].(string)
 	googleID, _ := payload.Claims["sub"].(string)
 
-	user, err := models.GetUserByGoogleID(ctx, googleID)
-	if err != nil {
-		user, err = models.GetUserByEmail(ctx, email)
-		if err != nil {
-			user = &models.User{
-				Email:    email,
-				Name:     name,
-				Avatar:   picture,
-				GoogleID: googleID,
-				Verified: true,
-			}
-			if err := models.CreateUserWithGoogle(ctx, user); err != nil {
-				utils.JSON(w, http.StatusInternalServerError, false, "cannot create user", nil)
-				return
-			}
-			user, err = models.GetUserByEmail(ctx, email)
-			if err != nil {
-				utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch user", nil)
-				return
-			}
-		} else {
-			user.GoogleID = googleID
-			user.Verified = true
-			if user.Avatar == "" {
-				user.Avatar = picture
-			}
-			if err := models.UpdateUser(ctx, user); err != nil {
-				utils.JSON(w, http.StatusInternalServerError, false, "cannot link Google account", nil)
-				return
-			}
+	email, err := utils.NormalizeEmail(rawEmail)
+	if err != nil || googleID == "" {
+		utils.JSON(w, http.StatusUnauthorized, false, "Google account has no usable email", nil)
+		return
+	}
+
+	user, err := s.Users.GetByGoogleID(ctx, googleID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+	if err == nil {
+		s.respondWithTokens(ctx, w, r, user, "login successful")
+		return
+	}
+
+	user, err = s.Users.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		user.GoogleID = googleID
+		if user.Avatar == "" {
+			user.Avatar = picture
+		}
+		if err := s.Users.LinkGoogle(ctx, user); err != nil {
+			serverError(w, r, "cannot link Google account", err)
+			return
+		}
+	case errors.Is(err, models.ErrNotFound):
+		user = &models.User{
+			Email:    email,
+			Name:     name,
+			Avatar:   picture,
+			GoogleID: googleID,
+			Verified: true,
 		}
+		if err := s.Users.CreateWithGoogle(ctx, user); err != nil {
+			serverError(w, r, "cannot create user", err)
+			return
+		}
+		if user, err = s.Users.GetByEmail(ctx, email); err != nil {
+			serverError(w, r, "cannot fetch user", err)
+			return
+		}
+	default:
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+
+	s.respondWithTokens(ctx, w, r, user, "login successful")
+}
+
+// Creates an unverified account and emails a verification link. The response
+// is identical whether or not the email is already registered; an existing
+// owner gets a notice instead of a second account.
+func (s *Server) registerHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+		return
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken()
+	email, err := utils.NormalizeEmail(req.Email)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate refresh token", nil)
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
-	if err := models.UpdateUserRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot save refresh token", nil)
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
 
-	accessToken, err := utils.GenerateJWT(user.ID, user.Role)
-	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate access token", nil)
+	ctx := r.Context()
+
+	const message = "check your email to verify your account"
+	hashed := utils.HashPassword(req.Password)
+
+	existing, err := s.Users.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		s.sendMail(ctx, mailer.Message{
+			To:      existing.Email,
+			Subject: "You already have a Bhinno account",
+			Body: "Someone tried to register a new Bhinno account with this email address, " +
+				"but you already have one.\n\n" +
+				"If you forgot your password, you can reset it from the login page. " +
+				"If this wasn't you, you can ignore this email.",
+		})
+		utils.JSON(w, http.StatusAccepted, true, message, nil)
+		return
+	case !errors.Is(err, models.ErrNotFound):
+		serverError(w, r, "cannot register user", err)
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, true, "login successful", map[string]any{
-		"user":          user,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	user := &models.User{
+		Email:    email,
+		Password: hashed,
+	}
+	if err := s.Users.CreateWithEmail(ctx, user); err != nil {
+		serverError(w, r, "cannot register user", err)
+		return
+	}
+
+	if err := s.sendVerificationMail(ctx, user); err != nil {
+		serverError(w, r, "cannot send verification email", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, true, message, nil)
 }
 
-func emailAuthHandler(w http.ResponseWriter, r *http.Request) {
-	type AuthRequest struct {
+// Unknown emails, accounts without a password and wrong passwords all get the
+// same answer, after the same bcrypt work.
+func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
 	}
 
-	var req AuthRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
 		return
@@ -122,91 +191,173 @@ func emailAuthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
-	user, err := models.GetUserByEmail(ctx, req.Email)
-	if err != nil {
-		hashedPassword := utils.HashPassword(req.Password)
-		user = &models.User{
-			Email:    req.Email,
-			Password: hashedPassword,
-		}
-		if err := models.CreateUserWithEmail(ctx, user); err != nil {
-			utils.JSON(w, http.StatusInternalServerError, false, "cannot create user", nil)
-			return
-		}
+	email, _ := utils.NormalizeEmail(req.Email)
 
-		user, err = models.GetUserByEmail(ctx, req.Email)
-		if err != nil {
-			utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch user", nil)
-			return
-		}
+	user, err := s.Users.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		serverError(w, r, "cannot log in", err)
+		return
 	}
 
-	if !utils.CheckHashAndPassword(req.Password, user.Password) {
-		utils.JSON(w, http.StatusUnauthorized, false, "invalid credentials", nil)
+	hash := dummyPasswordHash()
+	if user != nil && user.Password != "" {
+		hash = user.Password
+	}
+	if !utils.CheckHashAndPassword(hash, req.Password) || user == nil || user.Password == "" {
+		utils.JSON(w, http.StatusUnauthorized, false, "invalid email or password", nil)
 		return
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken()
+	if !user.Verified {
+		utils.JSON(w, http.StatusForbidden, false, "email not verified", nil)
+		return
+	}
+
+	s.respondWithTokens(ctx, w, r, user, "login successful")
+}
+
+func (s *Server) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "token required", nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	err := s.Users.VerifyEmail(ctx, utils.HashToken(req.Token))
+	if errors.Is(err, models.ErrNotFound) {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid or expired verification token", nil)
+		return
+	}
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate refresh token", nil)
+		serverError(w, r, "cannot verify email", err)
 		return
 	}
 
-	if err := models.UpdateUserRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot save refresh token", nil)
+	utils.JSON(w, http.StatusOK, true, "email verified, you can now log in", nil)
+}
+
+func (s *Server) resendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
 		return
 	}
 
-	accessToken, err := utils.GenerateJWT(user.ID, user.Role)
+	email, err := utils.NormalizeEmail(req.Email)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate access token", nil)
+		utils.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, true, "login successful", map[string]any{
-		"user":          user,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
+	ctx := r.Context()
+
+	const message = "if this email needs verification, a new link has been sent"
+
+	user, err := s.Users.GetByEmail(ctx, email)
+	if err != nil || user.Verified {
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			middlewares.Logger(r.Context()).Error("verification resend lookup failed", "error", err)
+		}
+		utils.JSON(w, http.StatusAccepted, true, message, nil)
+		return
+	}
+
+	if err := s.sendVerificationMail(ctx, user); err != nil {
+		serverError(w, r, "cannot send verification email", err)
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, true, message, nil)
+}
+
+// sendVerificationMail issues a fresh verification token, replacing any earlier one.
+func (s *Server) sendVerificationMail(ctx context.Context, user *models.User) error {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(s.cfg.EmailVerifyTTL) * time.Hour
+	if err := s.Users.SetVerifyToken(ctx, user.ID, utils.HashToken(token), time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	s.sendMail(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Bhinno email",
+		Body: "Welcome to Bhinno!\n\n" +
+			"Use this link within " + strconv.Itoa(s.cfg.EmailVerifyTTL) + " hours to verify your email address:\n" +
+			tokenLink(s.cfg.EmailVerifyURL, token),
 	})
+	return nil
 }
 
-func refreshSessionHandler(w http.ResponseWriter, r *http.Request) {
+// dummyPasswordHash is compared against when no real hash exists, so failed
+// logins for unknown emails cost the same as wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	return utils.HashPassword("not-a-real-password-0")
+})
+
+// Rotates the refresh token. The session still ends REFRESH_TOKEN_TTL_DAYS
+// after sign-in. Presenting a token that was already rotated means it leaked
+// or was replayed, so the whole session is revoked.
+func (s *Server) refreshSessionHandler(w http.ResponseWriter, r *http.Request) {
 	type RefreshRequest struct {
 		RefreshToken string `json:"refresh_token"`
 	}
 
 	var req RefreshRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.JSON(w, http.StatusBadRequest, false, "invalid request body", nil)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		utils.JSON(w, http.StatusBadRequest, false, "refresh_token required", nil)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
-	user, err := models.GetUserByRefreshToken(ctx, req.RefreshToken)
+	newRefreshToken, err := utils.GenerateRefreshToken()
 	if err != nil {
+		serverError(w, r, "cannot generate refresh token", err)
+		return
+	}
+
+	sess, err := s.Sessions.Rotate(ctx, utils.HashToken(req.RefreshToken), utils.HashToken(newRefreshToken))
+	switch {
+	case errors.Is(err, models.ErrRefreshTokenReused):
+		utils.JSON(w, http.StatusUnauthorized, false, "refresh token already used, session revoked", nil)
+		return
+	case errors.Is(err, models.ErrNotFound):
 		utils.JSON(w, http.StatusUnauthorized, false, "invalid refresh token", nil)
 		return
+	case err != nil:
+		serverError(w, r, "cannot refresh session", err)
+		return
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken()
+	user, err := s.Users.GetByID(ctx, sess.UserID)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate refresh token", nil)
+		serverError(w, r, "cannot fetch user", err)
 		return
 	}
 
-	if err := models.UpdateUserRefreshToken(ctx, user.ID, newRefreshToken); err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot save refresh token", nil)
+	if user.Blocked() {
+		_ = s.Sessions.Revoke(ctx, user.ID, sess.ID)
+		utils.JSON(w, http.StatusForbidden, false, "account is "+user.Status, nil)
 		return
 	}
 
-	accessToken, err := utils.GenerateJWT(user.ID, user.Role)
+	accessToken, err := utils.GenerateJWT(user.ID, user.Role, sess.ID)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot generate access token", nil)
+		serverError(w, r, "cannot generate access token", err)
 		return
 	}
 
@@ -217,9 +368,11 @@ func refreshSessionHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// Self-serve provider onboarding: a verified client turns their account into
+// a provider account so they can publish services. Roles are read from the
+// database on every request, so existing tokens pick it up at once.
+func (s *Server) becomeProviderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
 	if !ok || userID == 0 {
@@ -227,9 +380,44 @@ func getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := models.GetUserByID(ctx, userID)
+	user, err := s.Users.GetByID(ctx, userID)
 	if err != nil {
-		utils.JSON(w, http.StatusInternalServerError, false, "cannot fetch user", nil)
+		serverError(w, r, "cannot fetch user", err)
+		return
+	}
+	if user.Role != middlewares.CtxRoleClient {
+		utils.JSON(w, http.StatusConflict, false, "only client accounts can become providers", nil)
+		return
+	}
+	if !user.Verified {
+		utils.JSON(w, http.StatusForbidden, false, "verify your email or phone before becoming a provider", nil)
+		return
+	}
+
+	if err := s.Users.SetRole(ctx, user.ID, middlewares.CtxRoleProvider); err != nil {
+		serverError(w, r, "cannot update role", err)
+		return
+	}
+	s.recordAudit(r, "user.role", "user", user.ID, map[string]any{"role": user.Role}, map[string]any{"role": middlewares.CtxRoleProvider})
+	user.Role = middlewares.CtxRoleProvider
+
+	utils.JSON(w, http.StatusOK, true, "you can now publish services", map[string]any{
+		"user": user,
+	})
+}
+
+func (s *Server) getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := r.Context().Value(middlewares.CtxUserID).(int64)
+	if !ok || userID == 0 {
+		utils.JSON(w, http.StatusUnauthorized, false, "unauthorized", nil)
+		return
+	}
+
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		serverError(w, r, "cannot fetch user", err)
 		return
 	}
 
@@ -238,9 +426,8 @@ func getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func logoutHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
 	userIDVal := r.Context().Value(middlewares.CtxUserID)
 	userID, ok := userIDVal.(int64)
@@ -249,12 +436,17 @@ func logoutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = models.UpdateUserRefreshToken(ctx, userID, "")
+	// Only this device is signed out; other sessions stay valid
+	sessionID, _ := r.Context().Value(middlewares.CtxSessionID).(int64)
+	if err := s.Sessions.Revoke(ctx, userID, sessionID); err != nil && !errors.Is(err, models.ErrNotFound) {
+		serverError(w, r, "cannot log out", err)
+		return
+	}
 
 	utils.JSON(w, http.StatusOK, true, "logout successful", nil)
 }
 
-func getUserByIDHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) getUserByIDHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	userID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -262,10 +454,9 @@ func getUserByIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
-	user, err := models.GetUserByID(ctx, userID)
+	user, err := s.Users.GetByID(ctx, userID)
 	if err != nil {
 		utils.JSON(w, http.StatusNotFound, false, "user not found", nil)
 		return
@@ -0,0 +1,76 @@
+// Package search normalises text so that services can be found by English,
+// Bangla script or romanised Bangla queries.
+package search
+
+import "strings"
+
+// bangla maps Bengali script to the Latin letters people use when they type
+// Bangla on a Latin keyboard. Consonants carry no inherent vowel; Skeleton
+// drops the ambiguous vowels on both sides instead.
+var bangla = map[rune]string{
+	// Independent vowels
+	'অ': "o", 'আ': "a", 'ই': "i", 'ঈ': "i", 'উ': "u", 'ঊ': "u", 'ঋ': "ri",
+	'এ': "e", 'ঐ': "oi", 'ও': "o", 'ঔ': "ou",
+	// Vowel signs
+	'া': "a", 'ি': "i", 'ী': "i", 'ু': "u", 'ূ': "u", 'ৃ': "ri",
+	'ে': "e", 'ৈ': "oi", 'ো': "o", 'ৌ': "ou",
+	// Consonants
+	'ক': "k", 'খ': "kh", 'গ': "g", 'ঘ': "gh", 'ঙ': "ng",
+	'চ': "c", 'ছ': "ch", 'জ': "j", 'ঝ': "jh", 'ঞ': "n",
+	'ট': "t", 'ঠ': "th", 'ড': "d", 'ঢ': "dh", 'ণ': "n",
+	'ত': "t", 'থ': "th", 'দ': "d", 'ধ': "dh", 'ন': "n",
+	'প': "p", 'ফ': "ph", 'ব': "b", 'ভ': "bh", 'ম': "m",
+	'য': "j", 'র': "r", 'ল': "l", 'শ': "sh", 'ষ': "sh", 'স': "s", 'হ': "h",
+	'\u09DC': "r", '\u09DD': "rh", '\u09DF': "y", 'ৎ': "t", 'ং': "ng", 'ঃ': "h",
+	// Signs without a sound of their own
+	'ঁ': "", '্': "",
+	// Digits
+	'০': "0", '১': "1", '২': "2", '৩': "3", '৪': "4",
+	'৫': "5", '৬': "6", '৭': "7", '৮': "8", '৯': "9",
+}
+
+// nukta after ড, ঢ or য makes the letters also encoded as U+09DC, U+09DD and U+09DF.
+const nukta = '়'
+
+var withNukta = map[rune]rune{'ড': '\u09DC', 'ঢ': '\u09DD', 'য': '\u09DF'}
+
+// HasBangla reports whether s contains Bengali script.
+func HasBangla(s string) bool {
+	for _, r := range s {
+		if r >= 0x0980 && r <= 0x09FF {
+			return true
+		}
+	}
+	return false
+}
+
+// Romanize spells the Bengali script in s with Latin letters and leaves
+// everything else as it is.
+func Romanize(s string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		if r == nukta {
+			if n, ok := withNukta[prev]; ok {
+				// Replace the plain letter already written
+				plain := bangla[prev]
+				out := b.String()[:b.Len()-len(plain)]
+				b.Reset()
+				b.WriteString(out)
+				b.WriteString(bangla[n])
+				prev = n
+			}
+			continue
+		}
+		if r == 'য' && prev == '্' {
+			// The ya-phala after a consonant sounds like y, not j
+			b.WriteString("y")
+		} else if latin, ok := bangla[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
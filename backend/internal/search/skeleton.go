@@ -0,0 +1,61 @@
+package search
+
+import (
+	"backend/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Skeleton reduces s to a spelling-tolerant form for trigram matching.
+// Bengali script is romanised first, then the parts that romanised Bangla
+// spells inconsistently are folded: aspiration, the vowels a and o (Bangla's
+// inherent vowel is written either way, or not at all), doubled letters and a
+// few interchangeable consonants. "Mohammadpur", "mohammodpur" and
+// "মোহাম্মদপুর" all become "mhmdpur".
+func Skeleton(s string) string {
+	s = strings.ToLower(Romanize(s))
+	s = strings.NewReplacer("ee", "i", "oo", "u", "x", "ks", "q", "k", "z", "j", "f", "p", "v", "b", "w", "o", "y", "i").Replace(s)
+
+	var words []string
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		var b strings.Builder
+		var last rune
+		for i, r := range word {
+			if r == 'a' || r == 'o' {
+				continue
+			}
+			// Aspiration: kh, gh, ch, th, dh, ph, bh, sh
+			if r == 'h' && i > 0 && last != 0 && strings.ContainsRune("kgcjtdpbsr", last) {
+				continue
+			}
+			if r == last {
+				continue
+			}
+			b.WriteRune(r)
+			last = r
+		}
+		if b.Len() > 0 {
+			words = append(words, b.String())
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ServiceText joins the searchable fields of s: title, caption, area,
+// description and the string values of its features.
+func ServiceText(s *models.Service) string {
+	parts := []string{s.Title, s.Caption, s.Area, s.Description}
+
+	keys := make([]string, 0, len(s.Features))
+	for k := range s.Features {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := s.Features[k].(string); ok {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"backend/internal/models"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTitles caps how many distinct service titles the index keeps.
+const maxTitles = 5000
+
+// Suggester answers typeahead queries from an in-memory index of categories,
+// subcategories, the location hierarchy and service titles. Refresh rebuilds
+// it; queries never touch the database.
+type Suggester struct {
+	// building is held for a whole rebuild, so concurrent ones queue
+	building  sync.Mutex
+	mu        sync.RWMutex
+	entries   []entry
+	refreshed time.Time
+}
+
+type entry struct {
+	models.Suggestion
+	label     string
+	words     []string
+	skeletons []string
+}
+
+func newEntry(s models.Suggestion) entry {
+	label := strings.ToLower(s.Label)
+	return entry{
+		Suggestion: s,
+		label:      label,
+		words:      strings.Fields(label),
+		skeletons:  strings.Fields(Skeleton(s.Label)),
+	}
+}
+
+// Refreshed returns when the index was last built, zero if never.
+func (x *Suggester) Refreshed() time.Time {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.refreshed
+}
+
+// Ensure builds the index unless it was built already. Concurrent callers
+// wait for a single build.
+func (x *Suggester) Ensure(ctx context.Context, stores models.Stores) error {
+	x.building.Lock()
+	defer x.building.Unlock()
+	if !x.Refreshed().IsZero() {
+		return nil
+	}
+	return x.refresh(ctx, stores)
+}
+
+// Refresh rebuilds the index from stores. Popularity is the number of
+// active services in a category or place, or sharing a title.
+func (x *Suggester) Refresh(ctx context.Context, stores models.Stores) error {
+	x.building.Lock()
+	defer x.building.Unlock()
+	return x.refresh(ctx, stores)
+}
+
+func (x *Suggester) refresh(ctx context.Context, stores models.Stores) error {
+	counts, err := stores.Services.Popularity(ctx, maxTitles)
+	if err != nil {
+		return err
+	}
+	categories, err := stores.Categories.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	subCategories, err := stores.SubCategories.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	countries, err := stores.Locations.GetAllCountries(ctx)
+	if err != nil {
+		return err
+	}
+
+	var entries []entry
+	for _, c := range categories {
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:       models.SuggestionCategory,
+			Label:      c.Name,
+			Filter:     map[string]string{"category": id(c.ID)},
+			Popularity: counts.Categories[c.ID],
+		}))
+	}
+	for _, sc := range subCategories {
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:       models.SuggestionSubcategory,
+			Label:      sc.Name,
+			Filter:     map[string]string{"category": id(sc.CategoryID), "subcategory": id(sc.ID)},
+			Popularity: counts.Subcategories[sc.ID],
+		}))
+	}
+	for _, c := range countries {
+		loc, err := stores.Locations.GetByCode(ctx, c.CountryCode)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, locationEntries(loc, counts)...)
+	}
+
+	for _, t := range counts.Titles {
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:       models.SuggestionService,
+			Label:      t.Title,
+			Filter:     map[string]string{"q": t.Title},
+			Popularity: t.Count,
+		}))
+	}
+
+	x.mu.Lock()
+	x.entries, x.refreshed = entries, time.Now()
+	x.mu.Unlock()
+	return nil
+}
+
+// locationEntries lists the country and every level of its hierarchy, each
+// with the filter down to that level and its parents as context.
+func locationEntries(loc *models.Location, counts *models.ServicePopularity) []entry {
+	code := loc.CountryCode
+	entries := []entry{newEntry(models.Suggestion{
+		Kind:       models.SuggestionLocation,
+		Label:      loc.CountryName,
+		Filter:     map[string]string{"country": code},
+		Popularity: counts.Countries[code],
+	})}
+
+	states := map[int]*models.State{}
+	for _, s := range loc.States {
+		states[s.ID] = s
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:       models.SuggestionLocation,
+			Label:      s.Name,
+			Context:    loc.CountryName,
+			Filter:     map[string]string{"country": code, "state": strconv.Itoa(s.ID)},
+			Popularity: counts.States[models.PlaceKey{CountryCode: code, ID: s.ID}],
+		}))
+	}
+
+	areas := map[int]*models.AdministrativeArea{}
+	for _, a := range loc.AdministrativeAreas {
+		state, ok := states[a.StateID]
+		if !ok {
+			continue
+		}
+		areas[a.ID] = a
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:       models.SuggestionLocation,
+			Label:      a.Name,
+			Context:    state.Name + ", " + loc.CountryName,
+			Filter:     map[string]string{"country": code, "state": strconv.Itoa(a.StateID), "area": strconv.Itoa(a.ID)},
+			Popularity: counts.AdministrativeAreas[models.PlaceKey{CountryCode: code, ID: a.ID}],
+		}))
+	}
+
+	for _, a := range loc.SubAdministrativeAreas {
+		area, ok := areas[a.AdministrativeAreaID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, newEntry(models.Suggestion{
+			Kind:    models.SuggestionLocation,
+			Label:   a.Name,
+			Context: area.Name + ", " + states[area.StateID].Name + ", " + loc.CountryName,
+			Filter: map[string]string{
+				"country":  code,
+				"state":    strconv.Itoa(area.StateID),
+				"area":     strconv.Itoa(area.ID),
+				"sub_area": strconv.Itoa(a.ID),
+			},
+			Popularity: counts.SubAdministrativeAreas[models.PlaceKey{CountryCode: code, ID: a.ID}],
+		}))
+	}
+	return entries
+}
+
+// Suggest returns up to limit entries matching q. Labels starting with q
+// rank first, then labels with a word starting with each word of q, then
+// labels whose skeletons match, so romanised and Bangla script queries find
+// each other. Within a tier more popular and shorter labels come first.
+func (x *Suggester) Suggest(q string, limit int) []models.Suggestion {
+	q = strings.ToLower(strings.Join(strings.Fields(q), " "))
+	words := strings.Fields(q)
+	skeletons := strings.Fields(Skeleton(q))
+	// One or two letter skeletons match nearly everything
+	fuzzy := len(strings.Join(skeletons, "")) > 2
+
+	type hit struct {
+		e    *entry
+		tier int
+	}
+	var hits []hit
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	for i := range x.entries {
+		e := &x.entries[i]
+		switch {
+		case strings.HasPrefix(e.label, q):
+			hits = append(hits, hit{e, 3})
+		case prefixes(words, e.words):
+			hits = append(hits, hit{e, 2})
+		case fuzzy && prefixes(skeletons, e.skeletons):
+			hits = append(hits, hit{e, 1})
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b hit) int {
+		switch {
+		case a.tier != b.tier:
+			return b.tier - a.tier
+		case a.e.Popularity != b.e.Popularity:
+			return b.e.Popularity - a.e.Popularity
+		case len(a.e.Label) != len(b.e.Label):
+			return len(a.e.Label) - len(b.e.Label)
+		}
+		return strings.Compare(a.e.Label, b.e.Label)
+	})
+
+	suggestions := make([]models.Suggestion, 0, min(len(hits), limit))
+	for _, h := range hits[:min(len(hits), limit)] {
+		suggestions = append(suggestions, h.e.Suggestion)
+	}
+	return suggestions
+}
+
+// prefixes reports whether every query word starts some label word.
+func prefixes(query, label []string) bool {
+	if len(query) == 0 {
+		return false
+	}
+	for _, q := range query {
+		if !slices.ContainsFunc(label, func(w string) bool { return strings.HasPrefix(w, q) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func id(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,44 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/store/memory"
+)
+
+func TestEnsureBuildsOnce(t *testing.T) {
+	stores := memory.New()
+	ctx := context.Background()
+	if err := stores.Categories.Create(ctx, &models.Category{Name: "Plumbing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var x search.Suggester
+	if err := x.Ensure(ctx, stores); err != nil {
+		t.Fatal(err)
+	}
+	built := x.Refreshed()
+	if built.IsZero() {
+		t.Fatal("Ensure did not build the index")
+	}
+
+	if err := stores.Categories.Create(ctx, &models.Category{Name: "Pest control"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Ensure(ctx, stores); err != nil {
+		t.Fatal(err)
+	}
+	if !x.Refreshed().Equal(built) || len(x.Suggest("pest", 5)) != 0 {
+		t.Fatal("Ensure rebuilt a built index")
+	}
+
+	if err := x.Refresh(ctx, stores); err != nil {
+		t.Fatal(err)
+	}
+	if got := x.Suggest("pest", 5); len(got) != 1 || got[0].Label != "Pest control" {
+		t.Fatalf("Suggest after Refresh = %+v", got)
+	}
+}
@@ -0,0 +1,203 @@
+// Package seed loads the reference data shipped with the backend into the stores.
+package seed
+
+import (
+	"backend/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// A country folder holds one file per level of its hierarchy. Each file is an
+// object with a single array of {id, name, <parent>} entries; the array's key
+// varies between countries ("districts", "sub_administrative_area", ...).
+// Sub-area entries may add a latitude and longitude centroid. The bundled
+// bd data has none yet; it needs a sourced dataset, not hand-typed values.
+const (
+	statesFile   = "states.json"
+	areasFile    = "administrative_areas.json"
+	subAreasFile = "sub_administrative_areas.json"
+)
+
+// Change reports what seeding did to one country.
+type Change struct {
+	CountryCode string
+	// Action is "created", "updated" or "unchanged"
+	Action   string
+	States   int
+	Areas    int
+	SubAreas int
+}
+
+type countryEntry struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Flag string `json:"flag"`
+}
+
+// Locations upserts every country found under dir: countries.json names them
+// and each <code>/ folder holds the hierarchy. The whole directory is
+// validated before anything is written. With dryRun nothing is written but
+// the report is the same.
+func Locations(ctx context.Context, store models.LocationStore, dir string, dryRun bool) ([]Change, error) {
+	countries, err := loadCountries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, c := range countries {
+		change := Change{
+			CountryCode: c.CountryCode,
+			States:      len(c.States),
+			Areas:       len(c.AdministrativeAreas),
+			SubAreas:    len(c.SubAdministrativeAreas),
+		}
+
+		existing, err := store.GetByCode(ctx, c.CountryCode)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			change.Action, err = "created", nil
+			if !dryRun {
+				err = store.Create(ctx, c)
+			}
+		case err != nil:
+		case sameLocation(existing, c):
+			change.Action = "unchanged"
+		default:
+			change.Action = "updated"
+			if !dryRun {
+				err = store.Update(ctx, c)
+			}
+		}
+		if err != nil {
+			return changes, fmt.Errorf("seed %s: %w", c.CountryCode, err)
+		}
+
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func loadCountries(dir string) ([]*models.Location, error) {
+	var entries []countryEntry
+	if err := readJSON(filepath.Join(dir, "countries.json"), &entries); err != nil {
+		return nil, err
+	}
+
+	named := map[string]countryEntry{}
+	for _, e := range entries {
+		if e.Code == "" || e.Name == "" {
+			return nil, fmt.Errorf("countries.json: every country needs a code and a name")
+		}
+		if _, dup := named[e.Code]; dup {
+			return nil, fmt.Errorf("countries.json: duplicate country %q", e.Code)
+		}
+		named[e.Code] = e
+	}
+
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var countries []*models.Location
+	found := map[string]bool{}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		e, ok := named[d.Name()]
+		if !ok {
+			return nil, fmt.Errorf("folder %s has no entry in countries.json", d.Name())
+		}
+		c, err := loadCountry(filepath.Join(dir, d.Name()), e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.Name(), err)
+		}
+		countries = append(countries, c)
+		found[e.Code] = true
+	}
+
+	for code := range named {
+		if !found[code] {
+			return nil, fmt.Errorf("country %s in countries.json has no folder", code)
+		}
+	}
+
+	sort.Slice(countries, func(i, j int) bool { return countries[i].CountryCode < countries[j].CountryCode })
+	return countries, nil
+}
+
+// loadCountry reads a country folder and checks that every id is unique and
+// every area points at an existing parent. Each level is ordered by id, as
+// the stores return it.
+func loadCountry(dir string, e countryEntry) (*models.Location, error) {
+	loc := &models.Location{CountryCode: e.Code, CountryName: e.Name, CountryFlag: e.Flag}
+
+	var err error
+	if loc.States, err = readLevel[models.State](filepath.Join(dir, statesFile)); err != nil {
+		return nil, err
+	}
+	if loc.AdministrativeAreas, err = readLevel[models.AdministrativeArea](filepath.Join(dir, areasFile)); err != nil {
+		return nil, err
+	}
+	if loc.SubAdministrativeAreas, err = readLevel[models.SubAdministrativeArea](filepath.Join(dir, subAreasFile)); err != nil {
+		return nil, err
+	}
+	if err := loc.Validate(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(loc.States, func(i, j int) bool { return loc.States[i].ID < loc.States[j].ID })
+	sort.Slice(loc.AdministrativeAreas, func(i, j int) bool { return loc.AdministrativeAreas[i].ID < loc.AdministrativeAreas[j].ID })
+	sort.Slice(loc.SubAdministrativeAreas, func(i, j int) bool {
+		return loc.SubAdministrativeAreas[i].ID < loc.SubAdministrativeAreas[j].ID
+	})
+	return loc, nil
+}
+
+// readLevel decodes the entries of file, which must sit under the object's
+// only key.
+func readLevel[T any](file string) ([]*T, error) {
+	var lists map[string][]*T
+	if err := readJSON(file, &lists); err != nil {
+		return nil, err
+	}
+	if len(lists) != 1 {
+		return nil, fmt.Errorf("%s: want an object with a single list, found %d keys", filepath.Base(file), len(lists))
+	}
+	for _, entries := range lists {
+		return entries, nil
+	}
+	return nil, nil
+}
+
+func readJSON(file string, v any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	return nil
+}
+
+func sameLocation(a, b *models.Location) bool {
+	return a.CountryName == b.CountryName &&
+		a.CountryFlag == b.CountryFlag &&
+		sameLevel(a.States, b.States) &&
+		sameLevel(a.AdministrativeAreas, b.AdministrativeAreas) &&
+		sameLevel(a.SubAdministrativeAreas, b.SubAdministrativeAreas)
+}
+
+// sameLevel compares two levels ordered by id; nil and empty are equal.
+func sameLevel[T any](a, b []*T) bool {
+	return len(a) == 0 && len(b) == 0 || reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,27 @@
+// Package sms delivers text messages such as phone login codes.
+package sms
+
+import (
+	"context"
+	"log"
+)
+
+type Sender interface {
+	// Send delivers body to an E.164 phone number.
+	Send(ctx context.Context, to, body string) error
+}
+
+// New returns the sender selected by kind. Only "log" exists so far; a gateway
+// implementation slots in here.
+func New(kind string) Sender {
+	return LogSender{}
+}
+
+// LogSender writes messages, codes included, to the standard logger. Meant for
+// local development only.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, to, body string) error {
+	log.Printf("sms to=%s\n%s", to, body)
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+)
+
+type auditStore struct {
+	d *db
+}
+
+func (st *auditStore) Record(ctx context.Context, e *models.AuditEvent) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	e.ID = st.d.nextID()
+	e.CreatedAt = time.Now()
+	st.d.audit = append(st.d.audit, clone(e))
+	return nil
+}
+
+func (st *auditStore) Search(ctx context.Context, f models.AuditFilter) ([]*models.AuditEvent, int, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var events []*models.AuditEvent
+	for i := len(st.d.audit) - 1; i >= 0; i-- {
+		e := st.d.audit[i]
+		if f.ActorID != 0 && e.ActorID != f.ActorID ||
+			f.Action != "" && e.Action != f.Action ||
+			f.TargetType != "" && e.TargetType != f.TargetType ||
+			f.TargetID != "" && e.TargetID != f.TargetID ||
+			f.From != nil && e.CreatedAt.Before(*f.From) ||
+			f.To != nil && !e.CreatedAt.Before(*f.To) {
+			continue
+		}
+		events = append(events, clone(e))
+	}
+
+	total := len(events)
+	start := min(f.Offset, total)
+	end := min(start+f.Limit, total)
+	return events[start:end], total, nil
+}
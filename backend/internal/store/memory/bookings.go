@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type bookingStore struct {
+	d *db
+}
+
+func (st *bookingStore) Create(ctx context.Context, b *models.Booking) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	s, ok := st.d.services[b.ServiceID]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	b.ID = st.d.nextID()
+	b.ProviderID = s.UserID
+	b.Status = models.BookingStatusPending
+	b.CreatedAt = time.Now()
+	st.d.bookings[b.ID] = clone(b)
+	return nil
+}
+
+func (st *bookingStore) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	b, ok := st.d.bookings[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(b), nil
+}
+
+func (st *bookingStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Booking, error) {
+	return st.filter(func(b *models.Booking) bool { return b.UserID == userID }), nil
+}
+
+func (st *bookingStore) GetByProviderID(ctx context.Context, providerID int64) ([]*models.Booking, error) {
+	return st.filter(func(b *models.Booking) bool { return b.ProviderID == providerID }), nil
+}
+
+func (st *bookingStore) UpdateStatus(ctx context.Context, b *models.Booking, to string) error {
+	if err := models.ValidateBookingTransition(b.Status, to); err != nil {
+		return err
+	}
+
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.bookings[b.ID]
+	if !ok || stored.Status != b.Status {
+		return models.ErrBookingStatusChanged
+	}
+
+	stored.Status = to
+	b.Status = to
+	return nil
+}
+
+// filter returns matching bookings newest first.
+func (st *bookingStore) filter(match func(b *models.Booking) bool) []*models.Booking {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var bookings []*models.Booking
+	for _, b := range st.d.bookings {
+		if match(b) {
+			bookings = append(bookings, clone(b))
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].ID > bookings[j].ID })
+	return bookings
+}
+
+func (st *bookingStore) CountByStatus(ctx context.Context) (map[string]int, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	counts := map[string]int{}
+	for _, b := range st.d.bookings {
+		counts[b.Status]++
+	}
+	return counts, nil
+}
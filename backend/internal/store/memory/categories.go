@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+var errRestricted = errors.New("row is still referenced")
+
+type categoryStore struct {
+	d *db
+}
+
+func (st *categoryStore) Create(ctx context.Context, c *models.Category) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	c.ID = st.d.nextID()
+	c.CreatedAt = time.Now()
+	st.d.categories[c.ID] = clone(c)
+	return nil
+}
+
+func (st *categoryStore) GetByID(ctx context.Context, id int64) (*models.Category, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	c, ok := st.d.categories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(c), nil
+}
+
+func (st *categoryStore) GetAll(ctx context.Context) ([]*models.Category, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var categories []*models.Category
+	for _, c := range st.d.categories {
+		categories = append(categories, clone(c))
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+func (st *categoryStore) Update(ctx context.Context, c *models.Category) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.categories[c.ID]
+	if !ok {
+		return nil
+	}
+	stored.Name = c.Name
+	stored.Description = c.Description
+	return nil
+}
+
+// Delete cascades to sub-categories and is refused while services use the category.
+func (st *categoryStore) Delete(ctx context.Context, id int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, s := range st.d.services {
+		if s.CategoryID == id {
+			return errRestricted
+		}
+	}
+
+	delete(st.d.categories, id)
+	for scID, sc := range st.d.subCategories {
+		if sc.CategoryID == id {
+			delete(st.d.subCategories, scID)
+		}
+	}
+	return nil
+}
+
+type subCategoryStore struct {
+	d *db
+}
+
+func (st *subCategoryStore) Create(ctx context.Context, sc *models.SubCategory) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if _, ok := st.d.categories[sc.CategoryID]; !ok {
+		return errRestricted
+	}
+
+	sc.ID = st.d.nextID()
+	sc.CreatedAt = time.Now()
+	st.d.subCategories[sc.ID] = clone(sc)
+	return nil
+}
+
+func (st *subCategoryStore) GetByID(ctx context.Context, id int64) (*models.SubCategory, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	sc, ok := st.d.subCategories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(sc), nil
+}
+
+func (st *subCategoryStore) GetAll(ctx context.Context) ([]*models.SubCategory, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var subCategories []*models.SubCategory
+	for _, sc := range st.d.subCategories {
+		subCategories = append(subCategories, clone(sc))
+	}
+	sort.Slice(subCategories, func(i, j int) bool { return subCategories[i].ID < subCategories[j].ID })
+	return subCategories, nil
+}
+
+func (st *subCategoryStore) Update(ctx context.Context, sc *models.SubCategory) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.subCategories[sc.ID]
+	if !ok {
+		return nil
+	}
+	if _, ok := st.d.categories[sc.CategoryID]; !ok {
+		return errRestricted
+	}
+	stored.CategoryID = sc.CategoryID
+	stored.Name = sc.Name
+	stored.Description = sc.Description
+	return nil
+}
+
+func (st *subCategoryStore) Delete(ctx context.Context, id int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, s := range st.d.services {
+		if s.SubcategoryID == id {
+			return errRestricted
+		}
+	}
+
+	delete(st.d.subCategories, id)
+	return nil
+}
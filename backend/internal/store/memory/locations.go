@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type locationStore struct {
+	d *db
+}
+
+func (st *locationStore) GetAllCountries(ctx context.Context) ([]*models.Location, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var locations []*models.Location
+	for _, loc := range st.d.locations {
+		locations = append(locations, &models.Location{
+			CountryCode: loc.CountryCode,
+			CountryName: loc.CountryName,
+			CountryFlag: loc.CountryFlag,
+		})
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].CountryName < locations[j].CountryName })
+	return locations, nil
+}
+
+func (st *locationStore) GetByCode(ctx context.Context, code string) (*models.Location, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	loc, ok := st.d.locations[code]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneLocation(loc), nil
+}
+
+func (st *locationStore) Create(ctx context.Context, loc *models.Location) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if _, ok := st.d.locations[loc.CountryCode]; ok {
+		return errDuplicate
+	}
+	if loc.Validate() != nil {
+		return models.ErrInvalidLocation
+	}
+
+	loc.CreatedAt = time.Now()
+	st.d.locations[loc.CountryCode] = cloneLocation(loc)
+	return nil
+}
+
+func (st *locationStore) Update(ctx context.Context, loc *models.Location) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.locations[loc.CountryCode]
+	if !ok {
+		return nil
+	}
+	if loc.Validate() != nil {
+		return models.ErrInvalidLocation
+	}
+	for _, s := range st.d.services {
+		if s.CountryCode == loc.CountryCode && !inHierarchy(loc, s) {
+			return models.ErrLocationInUse
+		}
+	}
+
+	updated := cloneLocation(loc)
+	updated.CreatedAt = stored.CreatedAt
+	st.d.locations[loc.CountryCode] = updated
+	return nil
+}
+
+func (st *locationStore) Delete(ctx context.Context, code string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, s := range st.d.services {
+		if s.CountryCode == code {
+			return models.ErrLocationInUse
+		}
+	}
+
+	delete(st.d.locations, code)
+	return nil
+}
+
+func (st *locationStore) ListStates(ctx context.Context, code string) ([]*models.State, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	loc, ok := st.d.locations[code]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	states := []*models.State{}
+	for _, s := range loc.States {
+		states = append(states, clone(s))
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+func (st *locationStore) ListAdministrativeAreas(ctx context.Context, code string, stateID int) ([]*models.AdministrativeArea, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	loc, ok := st.d.locations[code]
+	if !ok || !hasState(loc, stateID) {
+		return nil, models.ErrNotFound
+	}
+
+	areas := []*models.AdministrativeArea{}
+	for _, a := range loc.AdministrativeAreas {
+		if a.StateID == stateID {
+			areas = append(areas, clone(a))
+		}
+	}
+	sort.SliceStable(areas, func(i, j int) bool { return areas[i].Name < areas[j].Name })
+	return areas, nil
+}
+
+func (st *locationStore) ListSubAdministrativeAreas(ctx context.Context, code string, areaID int) ([]*models.SubAdministrativeArea, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	loc, ok := st.d.locations[code]
+	if !ok || findArea(loc, areaID) == nil {
+		return nil, models.ErrNotFound
+	}
+
+	subAreas := []*models.SubAdministrativeArea{}
+	for _, a := range loc.SubAdministrativeAreas {
+		if a.AdministrativeAreaID == areaID {
+			subAreas = append(subAreas, clone(a))
+		}
+	}
+	sort.SliceStable(subAreas, func(i, j int) bool { return subAreas[i].Name < subAreas[j].Name })
+	return subAreas, nil
+}
+
+// inHierarchy mirrors the services foreign keys: the service's state, area
+// and sub-area must exist and form a chain.
+func inHierarchy(loc *models.Location, s *models.Service) bool {
+	if !hasState(loc, s.StateID) {
+		return false
+	}
+	if a := findArea(loc, s.AdministrativeAreaID); a == nil || a.StateID != s.StateID {
+		return false
+	}
+	for _, a := range loc.SubAdministrativeAreas {
+		if a.ID == s.SubAdministrativeAreaID {
+			return a.AdministrativeAreaID == s.AdministrativeAreaID
+		}
+	}
+	return false
+}
+
+func hasState(loc *models.Location, id int) bool {
+	for _, s := range loc.States {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func findArea(loc *models.Location, id int) *models.AdministrativeArea {
+	for _, a := range loc.AdministrativeAreas {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// cloneLocation deep copies loc with each level ordered by id, as the
+// postgres store returns it.
+func cloneLocation(loc *models.Location) *models.Location {
+	c := clone(loc)
+	c.States = cloneLevel(loc.States, func(s *models.State) int { return s.ID })
+	c.AdministrativeAreas = cloneLevel(loc.AdministrativeAreas, func(a *models.AdministrativeArea) int { return a.ID })
+	c.SubAdministrativeAreas = cloneLevel(loc.SubAdministrativeAreas, func(a *models.SubAdministrativeArea) int { return a.ID })
+	return c
+}
+
+func cloneLevel[T any](items []*T, id func(*T) int) []*T {
+	if items == nil {
+		return nil
+	}
+	out := make([]*T, 0, len(items))
+	for _, item := range items {
+		out = append(out, clone(item))
+	}
+	sort.Slice(out, func(i, j int) bool { return id(out[i]) < id(out[j]) })
+	return out
+}
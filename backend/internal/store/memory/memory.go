@@ -0,0 +1,67 @@
+// Package memory implements the models stores with in-process maps. It mirrors
+// the postgres stores closely enough to exercise handlers without a database.
+package memory
+
+import (
+	"backend/internal/models"
+	"sync"
+)
+
+// db holds every table behind one lock so stores that touch several tables
+// (bookings reading services, ratings updating users) stay consistent.
+type db struct {
+	mu            sync.RWMutex
+	seq           int64
+	users         map[int64]*models.User
+	categories    map[int64]*models.Category
+	subCategories map[int64]*models.SubCategory
+	locations     map[string]*models.Location
+	services      map[int64]*models.Service
+	bookings      map[int64]*models.Booking
+	ratings       map[int64]*models.Rating
+	otps          map[string]*models.PhoneOTP
+	sessions      map[int64]*models.Session
+	refreshTokens map[string]*refreshToken
+	audit         []*models.AuditEvent
+}
+
+// New returns every store backed by a fresh, empty in-memory database.
+func New() models.Stores {
+	d := &db{
+		users:         map[int64]*models.User{},
+		categories:    map[int64]*models.Category{},
+		subCategories: map[int64]*models.SubCategory{},
+		locations:     map[string]*models.Location{},
+		services:      map[int64]*models.Service{},
+		bookings:      map[int64]*models.Booking{},
+		ratings:       map[int64]*models.Rating{},
+		otps:          map[string]*models.PhoneOTP{},
+		sessions:      map[int64]*models.Session{},
+		refreshTokens: map[string]*refreshToken{},
+	}
+
+	return models.Stores{
+		Users:         &userStore{d},
+		Categories:    &categoryStore{d},
+		SubCategories: &subCategoryStore{d},
+		Locations:     &locationStore{d},
+		Services:      &serviceStore{d},
+		Bookings:      &bookingStore{d},
+		Ratings:       &ratingStore{d},
+		OTPs:          &otpStore{d},
+		Sessions:      &sessionStore{d},
+		Audit:         &auditStore{d},
+	}
+}
+
+// nextID hands out ids from a single sequence; callers hold d.mu.
+func (d *db) nextID() int64 {
+	d.seq++
+	return d.seq
+}
+
+// clone returns a shallow copy so callers cannot mutate stored rows in place.
+func clone[T any](v *T) *T {
+	c := *v
+	return &c
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"crypto/subtle"
+	"time"
+)
+
+type otpStore struct {
+	d *db
+}
+
+func (st *otpStore) Create(ctx context.Context, otp *models.PhoneOTP, limits models.OTPLimits) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	now := time.Now()
+	otp.Sends, otp.Failures, otp.WindowStart = 1, 0, now
+	if prev, ok := st.d.otps[otp.Phone]; ok && !limits.WindowOver(prev, now) {
+		if prev.Sends >= limits.Sends || prev.Failures >= limits.Failures {
+			return models.ErrOTPLocked
+		}
+		otp.Sends, otp.Failures, otp.WindowStart = prev.Sends+1, prev.Failures, prev.WindowStart
+	}
+
+	otp.Attempts = 0
+	otp.CreatedAt = now
+	st.d.otps[otp.Phone] = clone(otp)
+	return nil
+}
+
+func (st *otpStore) Get(ctx context.Context, phone string) (*models.PhoneOTP, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	otp, ok := st.d.otps[phone]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(otp), nil
+}
+
+func (st *otpStore) Consume(ctx context.Context, phone, codeHash string, limits models.OTPLimits) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	otp, ok := st.d.otps[phone]
+	if !ok || !otp.ExpiresAt.After(time.Now()) {
+		return models.ErrNotFound
+	}
+	if otp.Attempts >= limits.Attempts || otp.Failures >= limits.Failures {
+		return models.ErrOTPLocked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(otp.CodeHash), []byte(codeHash)) != 1 {
+		otp.Attempts++
+		otp.Failures++
+		if otp.Attempts >= limits.Attempts || otp.Failures >= limits.Failures {
+			return models.ErrOTPLocked
+		}
+		return models.ErrOTPMismatch
+	}
+
+	delete(st.d.otps, phone)
+	return nil
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+type ratingStore struct {
+	d *db
+}
+
+func (st *ratingStore) Create(ctx context.Context, rt *models.Rating) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, other := range st.d.ratings {
+		if other.BookingID == rt.BookingID {
+			return models.ErrRatingExists
+		}
+	}
+
+	rt.ID = st.d.nextID()
+	rt.CreatedAt = time.Now()
+	st.d.ratings[rt.ID] = clone(rt)
+	st.refreshAggregate(rt.ProviderID)
+	return nil
+}
+
+func (st *ratingStore) Update(ctx context.Context, rt *models.Rating) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.ratings[rt.ID]
+	if !ok {
+		return nil
+	}
+	stored.Rating = rt.Rating
+	stored.Comment = rt.Comment
+	st.refreshAggregate(stored.ProviderID)
+	return nil
+}
+
+func (st *ratingStore) Delete(ctx context.Context, rt *models.Rating) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	delete(st.d.ratings, rt.ID)
+	st.refreshAggregate(rt.ProviderID)
+	return nil
+}
+
+func (st *ratingStore) GetByID(ctx context.Context, id int64) (*models.Rating, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	rt, ok := st.d.ratings[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(rt), nil
+}
+
+func (st *ratingStore) GetByServiceID(ctx context.Context, serviceID int64) ([]*models.Rating, error) {
+	return st.filter(func(rt *models.Rating) bool { return rt.ServiceID == serviceID }), nil
+}
+
+func (st *ratingStore) GetByProviderID(ctx context.Context, providerID int64) ([]*models.Rating, error) {
+	return st.filter(func(rt *models.Rating) bool { return rt.ProviderID == providerID }), nil
+}
+
+func (st *ratingStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Rating, error) {
+	return st.filter(func(rt *models.Rating) bool { return rt.UserID == userID }), nil
+}
+
+// refreshAggregate recomputes the provider's rating_avg and rating_count; callers hold d.mu.
+func (st *ratingStore) refreshAggregate(providerID int64) {
+	u, ok := st.d.users[providerID]
+	if !ok {
+		return
+	}
+
+	var sum float64
+	var count int
+	for _, rt := range st.d.ratings {
+		if rt.ProviderID == providerID {
+			sum += rt.Rating
+			count++
+		}
+	}
+
+	u.RatingCount = count
+	u.RatingAvg = 0
+	if count > 0 {
+		u.RatingAvg = math.Round(sum/float64(count)*100) / 100
+	}
+}
+
+// filter returns matching ratings newest first.
+func (st *ratingStore) filter(match func(rt *models.Rating) bool) []*models.Rating {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var ratings []*models.Rating
+	for _, rt := range st.d.ratings {
+		if match(rt) {
+			ratings = append(ratings, clone(rt))
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].ID > ratings[j].ID })
+	return ratings
+}
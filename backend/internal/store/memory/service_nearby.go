@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"math"
+	"sort"
+)
+
+func (st *serviceStore) Nearby(ctx context.Context, f models.NearbyFilter) ([]*models.Service, int, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	services := []*models.Service{}
+	for _, s := range st.d.services {
+		if !s.Active || !st.ownerVisible(s) ||
+			f.CategoryID != 0 && s.CategoryID != f.CategoryID ||
+			f.SubcategoryID != 0 && s.SubcategoryID != f.SubcategoryID {
+			continue
+		}
+		lat, lng, ok := st.servicePoint(s)
+		if !ok {
+			continue
+		}
+		distance := models.DistanceKM(f.Latitude, f.Longitude, lat, lng)
+		if distance > f.RadiusKM {
+			continue
+		}
+		c := clone(s)
+		distance = math.Round(distance*1000) / 1000
+		c.DistanceKM = &distance
+		services = append(services, c)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if *services[i].DistanceKM != *services[j].DistanceKM {
+			return *services[i].DistanceKM < *services[j].DistanceKM
+		}
+		return services[i].ID < services[j].ID
+	})
+	total := len(services)
+	services = services[min(f.Offset, total):]
+	return services[:min(len(services), f.Limit)], total, nil
+}
+
+// servicePoint places s at its own coordinates or else at its sub-area's
+// centroid. The caller holds the read lock.
+func (st *serviceStore) servicePoint(s *models.Service) (lat, lng float64, ok bool) {
+	if s.Latitude != nil && s.Longitude != nil {
+		return *s.Latitude, *s.Longitude, true
+	}
+	loc, found := st.d.locations[s.CountryCode]
+	if !found {
+		return 0, 0, false
+	}
+	for _, a := range loc.SubAdministrativeAreas {
+		if a.ID == s.SubAdministrativeAreaID && a.Latitude != nil && a.Longitude != nil {
+			return *a.Latitude, *a.Longitude, true
+		}
+	}
+	return 0, 0, false
+}
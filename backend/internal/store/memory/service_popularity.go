@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (st *serviceStore) Popularity(ctx context.Context, titles int) (*models.ServicePopularity, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	p := models.NewServicePopularity()
+	byKey := map[string]*models.TitleCount{}
+	for _, s := range st.d.services {
+		if !s.Active || !st.ownerVisible(s) {
+			continue
+		}
+		p.AddCategory(s.CategoryID, s.SubcategoryID, 1)
+		p.AddPlace(s.CountryCode, s.StateID, s.AdministrativeAreaID, s.SubAdministrativeAreaID, 1)
+
+		title := strings.Join(strings.Fields(s.Title), " ")
+		key := strings.ToLower(title)
+		if key == "" {
+			continue
+		}
+		t, ok := byKey[key]
+		if !ok {
+			t = &models.TitleCount{Title: title}
+			byKey[key] = t
+		}
+		t.Title = min(t.Title, title)
+		t.Count++
+	}
+
+	for _, t := range byKey {
+		p.Titles = append(p.Titles, *t)
+	}
+	slices.SortFunc(p.Titles, func(a, b models.TitleCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Title, b.Title)
+	})
+	p.Titles = p.Titles[:min(len(p.Titles), titles)]
+	return p, nil
+}
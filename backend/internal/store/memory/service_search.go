@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// serviceHit is a search match with the sort keys kept beside it.
+type serviceHit struct {
+	s      *models.Service
+	rating float64
+}
+
+func (st *serviceStore) Search(ctx context.Context, f models.ServiceFilter) (*models.ServicePage, error) {
+	var after *serviceHit
+	if f.Cursor != "" {
+		cur, err := models.DecodeServiceCursor(f.Cursor, f.Sort)
+		if err != nil {
+			return nil, err
+		}
+		after = cursorHit(cur)
+	}
+
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var hits []serviceHit
+	for _, s := range st.d.services {
+		if !s.Active || !st.ownerVisible(s) || !matchesService(s, f) {
+			continue
+		}
+		h := serviceHit{s: clone(s), rating: st.d.users[s.UserID].RatingAvg}
+		if f.Query != "" {
+			var ok bool
+			if h.s.Rank, h.s.Snippet, ok = matchText(s, f.Query); !ok {
+				continue
+			}
+		}
+		hits = append(hits, h)
+	}
+	sort.Slice(hits, func(i, j int) bool { return hitBefore(f.Sort, hits[i], hits[j]) })
+
+	page := &models.ServicePage{Services: []*models.Service{}, Total: len(hits)}
+	for i, h := range hits {
+		if after != nil && !hitBefore(f.Sort, *after, h) {
+			continue
+		}
+		if len(page.Services) == f.Limit {
+			prev := hits[i-1]
+			page.NextCursor = models.NewServiceCursor(f.Sort, prev.s, prev.rating).Encode()
+			break
+		}
+		page.Services = append(page.Services, h.s)
+	}
+	return page, nil
+}
+
+func (st *serviceStore) Facets(ctx context.Context, f models.ServiceFilter) (*models.ServiceFacets, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	facets := &models.ServiceFacets{
+		Categories:    st.countFacet(f, models.ServiceFacetCategories, func(s *models.Service) []string { return []string{id(s.CategoryID)} }),
+		Subcategories: st.countFacet(f, models.ServiceFacetSubcategories, func(s *models.Service) []string { return []string{id(s.SubcategoryID)} }),
+		Days:          st.countFacet(f, models.ServiceFacetDays, func(s *models.Service) []string { return s.Days }),
+	}
+	if f.CountryCode != "" {
+		facets.States = st.countFacet(f, models.ServiceFacetStates, func(s *models.Service) []string { return []string{strconv.Itoa(s.StateID)} })
+		facets.AdministrativeAreas = st.countFacet(f, models.ServiceFacetAdministrativeAreas, func(s *models.Service) []string { return []string{strconv.Itoa(s.AdministrativeAreaID)} })
+	}
+	return facets, nil
+}
+
+// countFacet counts the services matching facet's filter under each of their
+// keys. The caller holds the read lock.
+func (st *serviceStore) countFacet(f models.ServiceFilter, facet string, keys func(*models.Service) []string) map[string]int {
+	f = f.ForFacet(facet)
+	counts := map[string]int{}
+	for _, s := range st.d.services {
+		if !s.Active || !st.ownerVisible(s) || !matchesService(s, f) {
+			continue
+		}
+		if f.Query != "" {
+			if _, _, ok := matchText(s, f.Query); !ok {
+				continue
+			}
+		}
+		for _, k := range keys(s) {
+			counts[k]++
+		}
+	}
+	return counts
+}
+
+func id(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func matchesService(s *models.Service, f models.ServiceFilter) bool {
+	if f.CountryCode != "" && s.CountryCode != f.CountryCode ||
+		f.StateID != 0 && s.StateID != f.StateID ||
+		f.AdministrativeAreaID != 0 && s.AdministrativeAreaID != f.AdministrativeAreaID ||
+		f.SubAdministrativeAreaID != 0 && s.SubAdministrativeAreaID != f.SubAdministrativeAreaID ||
+		f.CategoryID != 0 && s.CategoryID != f.CategoryID ||
+		f.SubcategoryID != 0 && s.SubcategoryID != f.SubcategoryID {
+		return false
+	}
+	for _, d := range f.Days {
+		if !slices.Contains(s.Days, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// hitBefore orders hits like the postgres search does.
+func hitBefore(order string, a, b serviceHit) bool {
+	switch order {
+	case models.ServiceSortRating:
+		if a.rating != b.rating {
+			return a.rating > b.rating
+		}
+		return a.s.ID > b.s.ID
+	case models.ServiceSortRelevance:
+		if a.s.Rank != b.s.Rank {
+			return a.s.Rank > b.s.Rank
+		}
+		return a.s.ID > b.s.ID
+	case models.ServiceSortPrice:
+		pa, pb := a.s.PriceAmount, b.s.PriceAmount
+		if (pa == nil) != (pb == nil) {
+			return pb == nil
+		}
+		if pa != nil && *pa != *pb {
+			return *pa < *pb
+		}
+		return a.s.ID < b.s.ID
+	default:
+		if !a.s.CreatedAt.Equal(b.s.CreatedAt) {
+			return a.s.CreatedAt.After(b.s.CreatedAt)
+		}
+		return a.s.ID > b.s.ID
+	}
+}
+
+// cursorHit rebuilds the sort keys of the service a cursor points after. The
+// cursor has already been validated.
+func cursorHit(cur *models.ServiceCursor) *serviceHit {
+	h := &serviceHit{s: &models.Service{ID: cur.ID}}
+	switch cur.Sort {
+	case models.ServiceSortRating:
+		h.rating, _ = strconv.ParseFloat(cur.Key, 64)
+	case models.ServiceSortRelevance:
+		h.s.Rank, _ = strconv.ParseFloat(cur.Key, 64)
+	case models.ServiceSortPrice:
+		if cur.Key != "" {
+			v, _ := strconv.ParseFloat(cur.Key, 64)
+			h.s.PriceAmount = &v
+		}
+	default:
+		h.s.CreatedAt, _ = time.Parse(time.RFC3339Nano, cur.Key)
+	}
+	return h
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type serviceStore struct {
+	d *db
+}
+
+func (st *serviceStore) Create(ctx context.Context, s *models.Service) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if err := st.checkRefs(s); err != nil {
+		return err
+	}
+
+	s.ID = st.d.nextID()
+	s.CreatedAt = time.Now()
+	s.PriceAmount = models.ParsePrice(s.Price)
+	st.d.services[s.ID] = clone(s)
+	return nil
+}
+
+func (st *serviceStore) GetByID(ctx context.Context, id int64) (*models.Service, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	s, ok := st.d.services[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(s), nil
+}
+
+func (st *serviceStore) Update(ctx context.Context, s *models.Service) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.services[s.ID]
+	if !ok {
+		return nil
+	}
+	if err := st.checkRefs(s); err != nil {
+		return err
+	}
+
+	s.PriceAmount = models.ParsePrice(s.Price)
+	updated := clone(s)
+	updated.UserID = stored.UserID
+	updated.CreatedAt = stored.CreatedAt
+	st.d.services[s.ID] = updated
+	return nil
+}
+
+// Delete cascades to the service's bookings and ratings like the foreign keys do.
+func (st *serviceStore) Delete(ctx context.Context, id int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	delete(st.d.services, id)
+	for bID, b := range st.d.bookings {
+		if b.ServiceID == id {
+			delete(st.d.bookings, bID)
+		}
+	}
+	for rID, rt := range st.d.ratings {
+		if rt.ServiceID == id {
+			delete(st.d.ratings, rID)
+		}
+	}
+	return nil
+}
+
+func (st *serviceStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Service, error) {
+	return st.filter(func(s *models.Service) bool { return s.UserID == userID }), nil
+}
+
+func (st *serviceStore) List(ctx context.Context) ([]*models.Service, error) {
+	return st.filter(func(s *models.Service) bool { return s.Active && st.ownerVisible(s) }), nil
+}
+
+// checkRefs enforces the services foreign keys; callers hold d.mu.
+func (st *serviceStore) checkRefs(s *models.Service) error {
+	if _, ok := st.d.users[s.UserID]; !ok {
+		return errRestricted
+	}
+	if _, ok := st.d.categories[s.CategoryID]; !ok {
+		return errRestricted
+	}
+	if _, ok := st.d.subCategories[s.SubcategoryID]; !ok {
+		return errRestricted
+	}
+	loc, ok := st.d.locations[s.CountryCode]
+	if !ok {
+		return errRestricted
+	}
+	if !inHierarchy(loc, s) {
+		return models.ErrInvalidLocation
+	}
+	return nil
+}
+
+// ownerVisible hides the services of suspended and banned users from
+// listings; callers hold d.mu.
+func (st *serviceStore) ownerVisible(s *models.Service) bool {
+	owner, ok := st.d.users[s.UserID]
+	return ok && !owner.Blocked()
+}
+
+// filter returns matching services newest first.
+func (st *serviceStore) filter(match func(s *models.Service) bool) []*models.Service {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var services []*models.Service
+	for _, s := range st.d.services {
+		if match(s) {
+			services = append(services, clone(s))
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if !services[i].CreatedAt.Equal(services[j].CreatedAt) {
+			return services[i].CreatedAt.After(services[j].CreatedAt)
+		}
+		return services[i].ID > services[j].ID
+	})
+	return services
+}
+
+func (st *serviceStore) CountActiveByCountry(ctx context.Context) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, s := range st.filter(func(s *models.Service) bool { return s.Active && st.ownerVisible(s) }) {
+		counts[s.CountryCode]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type refreshToken struct {
+	sessionID int64
+	usedAt    *time.Time
+}
+
+type sessionStore struct {
+	d *db
+}
+
+func (st *sessionStore) Create(ctx context.Context, sess *models.Session, tokenHash string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if _, ok := st.d.users[sess.UserID]; !ok {
+		return errRestricted
+	}
+
+	now := time.Now()
+	sess.ID = st.d.nextID()
+	sess.CreatedAt, sess.LastUsedAt = now, now
+	sess.RevokedAt = nil
+	st.d.sessions[sess.ID] = clone(sess)
+	st.d.refreshTokens[tokenHash] = &refreshToken{sessionID: sess.ID}
+	return nil
+}
+
+func (st *sessionStore) Rotate(ctx context.Context, tokenHash, newHash string) (*models.Session, error) {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	rt, ok := st.d.refreshTokens[tokenHash]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	sess := st.d.sessions[rt.sessionID]
+	now := time.Now()
+
+	if rt.usedAt != nil {
+		if sess.RevokedAt == nil {
+			sess.RevokedAt = &now
+		}
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	if sess.RevokedAt != nil || !sess.ExpiresAt.After(now) {
+		return nil, models.ErrNotFound
+	}
+
+	sess.LastUsedAt = now
+	rt.usedAt = &now
+	st.d.refreshTokens[newHash] = &refreshToken{sessionID: sess.ID}
+	return clone(sess), nil
+}
+
+func (st *sessionStore) GetByID(ctx context.Context, id int64) (*models.Session, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	sess, ok := st.d.sessions[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return clone(sess), nil
+}
+
+func (st *sessionStore) ListActive(ctx context.Context, userID int64) ([]*models.Session, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var sessions []*models.Session
+	for _, sess := range st.d.sessions {
+		if sess.UserID == userID && sessionActive(sess) {
+			sessions = append(sessions, clone(sess))
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (st *sessionStore) Revoke(ctx context.Context, userID, id int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	sess, ok := st.d.sessions[id]
+	if !ok || sess.UserID != userID || !sessionActive(sess) {
+		return models.ErrNotFound
+	}
+
+	now := time.Now()
+	sess.RevokedAt = &now
+	return nil
+}
+
+func (st *sessionStore) RevokeAll(ctx context.Context, userID int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	st.d.revokeSessions(userID)
+	return nil
+}
+
+// revokeSessions revokes every open session of the user; callers hold d.mu.
+func (d *db) revokeSessions(userID int64) {
+	now := time.Now()
+	for _, sess := range d.sessions {
+		if sess.UserID == userID && sess.RevokedAt == nil {
+			sess.RevokedAt = &now
+		}
+	}
+}
+
+func sessionActive(sess *models.Session) bool {
+	return sess.RevokedAt == nil && sess.ExpiresAt.After(time.Now())
+}
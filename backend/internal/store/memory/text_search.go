@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"backend/internal/search"
+	"html"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// matchText approximates the postgres text search: every query word found
+// in a field scores that field's weight, as ts_rank_cd weighs A to D, and a
+// loose match of the skeletons adds their similarity. The snippet marks the
+// exact word matches.
+func matchText(s *models.Service, query string) (rank float64, snippet string, ok bool) {
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{s.Title, 1}, {s.Caption, 0.4}, {s.Area, 0.4}, {s.Description, 0.2},
+	}
+	for _, v := range s.Features {
+		if v, isString := v.(string); isString {
+			fields = append(fields, struct {
+				text   string
+				weight float64
+			}{v, 0.1})
+		}
+	}
+
+	terms := words(query)
+	matched := len(terms) > 0
+	var score float64
+	for _, term := range terms {
+		best := 0.0
+		for _, field := range fields {
+			if field.weight > best && contains(words(field.text), term) {
+				best = field.weight
+			}
+		}
+		if best == 0 {
+			matched = false
+		}
+		score += best
+	}
+	if !matched {
+		score = 0
+	}
+
+	similarity := skeletonSimilarity(search.Skeleton(query), search.Skeleton(search.ServiceText(s)))
+	if !matched && similarity < 0.6 {
+		return 0, "", false
+	}
+
+	rank = math.Round((score*2+similarity)*1e6) / 1e6
+	return rank, highlight(s, terms), true
+}
+
+// skeletonSimilarity is the share of query words that some document word
+// spells within one edit in four letters.
+func skeletonSimilarity(query, doc string) float64 {
+	q, d := strings.Fields(query), strings.Fields(doc)
+	if len(q) == 0 {
+		return 0
+	}
+	found := 0
+	for _, qw := range q {
+		for _, dw := range d {
+			if editDistance(qw, dw) <= len([]rune(qw))/4 {
+				found++
+				break
+			}
+		}
+	}
+	return float64(found) / float64(len(q))
+}
+
+// highlight escapes the searchable text and wraps the words equal to a term
+// in <mark>.
+func highlight(s *models.Service, terms []string) string {
+	var fields []string
+	for _, f := range []string{s.Title, s.Caption, s.Area, s.Description} {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	parts := strings.Fields(strings.Join(fields, " · "))
+	for i, part := range parts {
+		escaped := html.EscapeString(part)
+		w := words(part)
+		if len(w) == 1 && contains(terms, w[0]) {
+			escaped = "<mark>" + escaped + "</mark>"
+		}
+		parts[i] = escaped
+	}
+	return strings.Join(parts, " ")
+}
+
+// words lower-cases s and splits it into words, keeping Bangla vowel signs
+// with their letters.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
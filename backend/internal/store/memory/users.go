@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var errDuplicate = errors.New("duplicate key")
+
+type userStore struct {
+	d *db
+}
+
+func (st *userStore) EnsureSuperAdmin(ctx context.Context, email, hashedPassword string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, u := range st.d.users {
+		if u.Role == "superadmin" {
+			u.Email = email
+			u.Password = hashedPassword
+			u.Status = models.UserStatusActive
+			return nil
+		}
+	}
+
+	return st.insert(&models.User{Email: email, Password: hashedPassword, Role: "superadmin", Status: models.UserStatusActive, Verified: true})
+}
+
+func (st *userStore) CreateWithGoogle(ctx context.Context, u *models.User) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	return st.insert(&models.User{
+		Email:    u.Email,
+		GoogleID: u.GoogleID,
+		Name:     u.Name,
+		Avatar:   u.Avatar,
+		Verified: u.Verified,
+	})
+}
+
+func (st *userStore) CreateWithEmail(ctx context.Context, u *models.User) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored := &models.User{Email: u.Email, Password: u.Password}
+	if err := st.insert(stored); err != nil {
+		return err
+	}
+	u.ID, u.Role, u.Status, u.CreatedAt = stored.ID, stored.Role, stored.Status, stored.CreatedAt
+	return nil
+}
+
+func (st *userStore) CreateWithPhone(ctx context.Context, u *models.User) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored := &models.User{Phone: u.Phone, Verified: u.Verified}
+	if err := st.insert(stored); err != nil {
+		return err
+	}
+	u.ID, u.Role, u.Status, u.CreatedAt = stored.ID, stored.Role, stored.Status, stored.CreatedAt
+	return nil
+}
+
+func (st *userStore) GetByID(ctx context.Context, userID int64) (*models.User, error) {
+	return st.find(func(u *models.User) bool { return u.ID == userID })
+}
+
+func (st *userStore) GetByGoogleID(ctx context.Context, googleID string) (*models.User, error) {
+	return st.find(func(u *models.User) bool { return googleID != "" && u.GoogleID == googleID })
+}
+
+func (st *userStore) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return st.find(func(u *models.User) bool { return phone != "" && u.Phone == phone })
+}
+
+func (st *userStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return st.find(func(u *models.User) bool { return email != "" && u.Email == email })
+}
+
+func (st *userStore) Update(ctx context.Context, u *models.User) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.users[u.ID]
+	if !ok {
+		return nil
+	}
+	if err := st.checkUnique(u); err != nil {
+		return err
+	}
+
+	stored.Phone = u.Phone
+	stored.Name = u.Name
+	stored.Email = u.Email
+	stored.Avatar = u.Avatar
+	stored.Bio = u.Bio
+	stored.Verified = u.Verified
+	stored.Status = u.Status
+	stored.GoogleID = u.GoogleID
+	return nil
+}
+
+func (st *userStore) LinkGoogle(ctx context.Context, u *models.User) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	stored, ok := st.d.users[u.ID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if !stored.Verified {
+		stored.Password = ""
+		st.d.revokeSessions(u.ID)
+	}
+	stored.GoogleID = u.GoogleID
+	stored.Avatar = u.Avatar
+	stored.Verified = true
+	stored.VerifyToken = ""
+	stored.VerifyTokenExpiry = nil
+
+	u.Password = stored.Password
+	u.Verified = true
+	return nil
+}
+
+func (st *userStore) Search(ctx context.Context, f models.UserFilter) ([]*models.User, int, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	var users []*models.User
+	for _, u := range st.d.users {
+		if f.Email != "" && !strings.Contains(strings.ToLower(u.Email), strings.ToLower(f.Email)) ||
+			f.Phone != "" && !strings.Contains(u.Phone, f.Phone) ||
+			f.Role != "" && u.Role != f.Role ||
+			f.Status != "" && u.Status != f.Status ||
+			f.CreatedFrom != nil && u.CreatedAt.Before(*f.CreatedFrom) ||
+			f.CreatedTo != nil && !u.CreatedAt.Before(*f.CreatedTo) {
+			continue
+		}
+		users = append(users, clone(u))
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.After(users[j].CreatedAt)
+		}
+		return users[i].ID > users[j].ID
+	})
+
+	total := len(users)
+	start := min(f.Offset, total)
+	end := min(start+f.Limit, total)
+	return users[start:end], total, nil
+}
+
+func (st *userStore) SetStatus(ctx context.Context, userID int64, status, reason string, changedBy int64) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	u, ok := st.d.users[userID]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	now := time.Now()
+	u.Status = status
+	u.StatusReason = reason
+	u.StatusChangedAt = &now
+	u.StatusChangedBy = changedBy
+	return nil
+}
+
+func (st *userStore) SetRole(ctx context.Context, userID int64, role string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	u, ok := st.d.users[userID]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	u.Role = role
+	return nil
+}
+
+func (st *userStore) SetResetToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if u, ok := st.d.users[userID]; ok {
+		u.ResetToken = tokenHash
+		u.ResetTokenExpiry = &expiry
+	}
+	return nil
+}
+
+func (st *userStore) ResetPassword(ctx context.Context, tokenHash, hashedPassword string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, u := range st.d.users {
+		if tokenHash == "" || u.ResetToken != tokenHash {
+			continue
+		}
+		if u.ResetTokenExpiry == nil || !u.ResetTokenExpiry.After(time.Now()) {
+			return models.ErrNotFound
+		}
+
+		u.Password = hashedPassword
+		u.ResetToken = ""
+		u.ResetTokenExpiry = nil
+		u.Verified = true
+		u.VerifyToken = ""
+		u.VerifyTokenExpiry = nil
+		st.d.revokeSessions(u.ID)
+		return nil
+	}
+	return models.ErrNotFound
+}
+
+func (st *userStore) SetVerifyToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	if u, ok := st.d.users[userID]; ok {
+		u.VerifyToken = tokenHash
+		u.VerifyTokenExpiry = &expiry
+	}
+	return nil
+}
+
+func (st *userStore) VerifyEmail(ctx context.Context, tokenHash string) error {
+	st.d.mu.Lock()
+	defer st.d.mu.Unlock()
+
+	for _, u := range st.d.users {
+		if tokenHash == "" || u.VerifyToken != tokenHash {
+			continue
+		}
+		if u.VerifyTokenExpiry == nil || !u.VerifyTokenExpiry.After(time.Now()) {
+			return models.ErrNotFound
+		}
+
+		u.Verified = true
+		u.VerifyToken = ""
+		u.VerifyTokenExpiry = nil
+		return nil
+	}
+	return models.ErrNotFound
+}
+
+// insert applies the column defaults and unique constraints of the users table; callers hold d.mu.
+func (st *userStore) insert(u *models.User) error {
+	if err := st.checkUnique(u); err != nil {
+		return err
+	}
+	if u.Role == "" {
+		u.Role = "client"
+	}
+	if u.Status == "" {
+		u.Status = models.UserStatusReview
+	}
+	u.ID = st.d.nextID()
+	u.CreatedAt = time.Now()
+	st.d.users[u.ID] = u
+	return nil
+}
+
+func (st *userStore) checkUnique(u *models.User) error {
+	for _, other := range st.d.users {
+		if other.ID == u.ID {
+			continue
+		}
+		if (u.Email != "" && other.Email == u.Email) ||
+			(u.Phone != "" && other.Phone == u.Phone) ||
+			(u.GoogleID != "" && other.GoogleID == u.GoogleID) {
+			return errDuplicate
+		}
+	}
+	return nil
+}
+
+func (st *userStore) find(match func(u *models.User) bool) (*models.User, error) {
+	st.d.mu.RLock()
+	defer st.d.mu.RUnlock()
+
+	for _, u := range st.d.users {
+		if match(u) {
+			return clone(u), nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
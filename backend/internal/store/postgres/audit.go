@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditStore struct {
+	pool *pgxpool.Pool
+}
+
+const auditColumns = `
+	id, COALESCE(actor_id, 0), COALESCE(actor_role, ''), action, target_type, target_id,
+	diff, COALESCE(ip, ''), COALESCE(request_id, ''), created_at`
+
+func scanAuditEvent(row pgx.Row, extra ...any) (*models.AuditEvent, error) {
+	e := &models.AuditEvent{}
+	dest := []any{
+		&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.TargetType, &e.TargetID,
+		&e.Diff, &e.IP, &e.RequestID, &e.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (st *AuditStore) Record(ctx context.Context, e *models.AuditEvent) error {
+	return st.pool.QueryRow(ctx, `
+		INSERT INTO audit_events (actor_id, actor_role, action, target_type, target_id, diff, ip, request_id)
+		VALUES (NULLIF($1, 0), NULLIF($2, ''), $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))
+		RETURNING id, created_at
+	`, e.ActorID, e.ActorRole, e.Action, e.TargetType, e.TargetID, e.Diff, e.IP, e.RequestID).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (st *AuditStore) Search(ctx context.Context, f models.AuditFilter) ([]*models.AuditEvent, int, error) {
+	var c conditions
+	if f.ActorID != 0 {
+		c.add(`actor_id = ?`, f.ActorID)
+	}
+	if f.Action != "" {
+		c.add(`action = ?`, f.Action)
+	}
+	if f.TargetType != "" {
+		c.add(`target_type = ?`, f.TargetType)
+	}
+	if f.TargetID != "" {
+		c.add(`target_id = ?`, f.TargetID)
+	}
+	if f.From != nil {
+		c.add(`created_at >= ?`, *f.From)
+	}
+	if f.To != nil {
+		c.add(`created_at < ?`, *f.To)
+	}
+
+	where, filterArgs := c.clause(), c.args
+	rows, err := st.pool.Query(ctx, `
+		SELECT `+auditColumns+`, COUNT(*) OVER ()
+		FROM audit_events`+where+`
+		ORDER BY id DESC
+		LIMIT `+c.arg(f.Limit)+` OFFSET `+c.arg(f.Offset), c.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var events []*models.AuditEvent
+	var total int
+	for rows.Next() {
+		e, err := scanAuditEvent(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// A page past the last row carries no window count
+	if len(events) == 0 && f.Offset > 0 {
+		if err := st.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events`+where, filterArgs...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return events, total, nil
+}
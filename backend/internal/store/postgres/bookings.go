@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BookingStore struct {
+	pool *pgxpool.Pool
+}
+
+const bookingColumns = `id, service_id, user_id, provider_id, COALESCE(hours, ''), days, status, created_at`
+
+func scanBooking(row pgx.Row) (*models.Booking, error) {
+	b := &models.Booking{}
+	err := row.Scan(
+		&b.ID, &b.ServiceID, &b.UserID, &b.ProviderID,
+		&b.Hours, &b.Days, &b.Status, &b.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (st *BookingStore) Create(ctx context.Context, b *models.Booking) error {
+	err := st.pool.QueryRow(ctx, `
+		INSERT INTO bookings (service_id, user_id, provider_id, hours, days, status)
+		SELECT s.id, $2, s.user_id, NULLIF($3, ''), $4, 'pending'
+		FROM services s
+		WHERE s.id=$1
+		RETURNING id, provider_id, status, created_at
+	`, b.ServiceID, b.UserID, b.Hours, b.Days).Scan(&b.ID, &b.ProviderID, &b.Status, &b.CreatedAt)
+	return notFound(err)
+}
+
+func (st *BookingStore) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
+	b, err := scanBooking(st.pool.QueryRow(ctx, `
+		SELECT `+bookingColumns+`
+		FROM bookings
+		WHERE id=$1
+	`, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return b, nil
+}
+
+func (st *BookingStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Booking, error) {
+	return st.query(ctx, `
+		SELECT `+bookingColumns+`
+		FROM bookings
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`, userID)
+}
+
+func (st *BookingStore) GetByProviderID(ctx context.Context, providerID int64) ([]*models.Booking, error) {
+	return st.query(ctx, `
+		SELECT `+bookingColumns+`
+		FROM bookings
+		WHERE provider_id=$1
+		ORDER BY created_at DESC
+	`, providerID)
+}
+
+// The update only applies if the booking is still in the status the
+// transition was validated against, so two concurrent transitions cannot
+// both succeed.
+func (st *BookingStore) UpdateStatus(ctx context.Context, b *models.Booking, to string) error {
+	if err := models.ValidateBookingTransition(b.Status, to); err != nil {
+		return err
+	}
+
+	tag, err := st.pool.Exec(ctx, `
+		UPDATE bookings
+		SET status=$1
+		WHERE id=$2 AND status=$3
+	`, to, b.ID, b.Status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrBookingStatusChanged
+	}
+
+	b.Status = to
+	return nil
+}
+
+func (st *BookingStore) query(ctx context.Context, query string, args ...any) ([]*models.Booking, error) {
+	rows, err := st.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (st *BookingStore) CountByStatus(ctx context.Context) (map[string]int, error) {
+	return countBy(ctx, st.pool, `SELECT status, COUNT(*) FROM bookings GROUP BY status`)
+}
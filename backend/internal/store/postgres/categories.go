@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CategoryStore struct {
+	pool *pgxpool.Pool
+}
+
+func (st *CategoryStore) Create(ctx context.Context, c *models.Category) error {
+	return st.pool.QueryRow(ctx, `
+		INSERT INTO categories (name, description)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`, c.Name, c.Description).Scan(&c.ID, &c.CreatedAt)
+}
+
+func (st *CategoryStore) GetByID(ctx context.Context, id int64) (*models.Category, error) {
+	c := &models.Category{}
+	err := st.pool.QueryRow(ctx, `
+		SELECT id, name, description, created_at
+		FROM categories
+		WHERE id = $1
+	`, id).Scan(
+		&c.ID,
+		&c.Name,
+		&c.Description,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return c, nil
+}
+
+func (st *CategoryStore) GetAll(ctx context.Context) ([]*models.Category, error) {
+	rows, err := st.pool.Query(ctx, `
+		SELECT id, name, description, created_at
+		FROM categories
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*models.Category
+	for rows.Next() {
+		c := &models.Category{}
+		if err := rows.Scan(
+			&c.ID,
+			&c.Name,
+			&c.Description,
+			&c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (st *CategoryStore) Update(ctx context.Context, c *models.Category) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE categories
+		SET name = $1,
+		    description = $2
+		WHERE id = $3
+	`, c.Name, c.Description, c.ID)
+
+	return err
+}
+
+func (st *CategoryStore) Delete(ctx context.Context, id int64) error {
+	_, err := st.pool.Exec(ctx, `
+		DELETE FROM categories
+		WHERE id = $1
+	`, id)
+
+	return err
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LocationStore struct {
+	pool *pgxpool.Pool
+}
+
+// Get all countries (for users, without the hierarchy)
+func (st *LocationStore) GetAllCountries(ctx context.Context) ([]*models.Location, error) {
+	rows, err := st.pool.Query(ctx, `
+		SELECT country_code, country_name, country_flag
+		FROM locations
+		ORDER BY country_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*models.Location
+	for rows.Next() {
+		loc := &models.Location{}
+		if err := rows.Scan(&loc.CountryCode, &loc.CountryName, &loc.CountryFlag); err != nil {
+			return nil, err
+		}
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
+
+// Get full country by code
+func (st *LocationStore) GetByCode(ctx context.Context, code string) (*models.Location, error) {
+	loc := &models.Location{}
+	err := st.pool.QueryRow(ctx, `
+		SELECT country_code, country_name, country_flag, created_at
+		FROM locations
+		WHERE country_code=$1
+	`, code).Scan(&loc.CountryCode, &loc.CountryName, &loc.CountryFlag, &loc.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if loc.States, err = collect[models.State](ctx, st.pool, `
+		SELECT id, name FROM states WHERE country_code=$1 ORDER BY id
+	`, code); err != nil {
+		return nil, err
+	}
+	if loc.AdministrativeAreas, err = collect[models.AdministrativeArea](ctx, st.pool, `
+		SELECT id, state_id, name FROM administrative_areas WHERE country_code=$1 ORDER BY id
+	`, code); err != nil {
+		return nil, err
+	}
+	if loc.SubAdministrativeAreas, err = collect[models.SubAdministrativeArea](ctx, st.pool, `
+		SELECT id, administrative_area_id, name, latitude, longitude
+		FROM sub_administrative_areas
+		WHERE country_code=$1
+		ORDER BY id
+	`, code); err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// Admin: create location
+func (st *LocationStore) Create(ctx context.Context, loc *models.Location) error {
+	return st.withTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO locations (country_code, country_name, country_flag)
+			VALUES ($1,$2,$3)
+			RETURNING created_at
+		`, loc.CountryCode, loc.CountryName, loc.CountryFlag).Scan(&loc.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return upsertHierarchy(ctx, tx, loc)
+	})
+}
+
+// Admin: update location. Levels are upserted top down and stale rows
+// removed bottom up, so areas may move between states in one update.
+func (st *LocationStore) Update(ctx context.Context, loc *models.Location) error {
+	return st.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE locations
+			SET country_name=$1, country_flag=$2
+			WHERE country_code=$3
+		`, loc.CountryName, loc.CountryFlag, loc.CountryCode)
+		if err != nil {
+			return err
+		}
+		if err := upsertHierarchy(ctx, tx, loc); err != nil {
+			return err
+		}
+
+		stale := []struct {
+			table string
+			ids   []int
+		}{
+			{"sub_administrative_areas", ids(loc.SubAdministrativeAreas, func(a *models.SubAdministrativeArea) int { return a.ID })},
+			{"administrative_areas", ids(loc.AdministrativeAreas, func(a *models.AdministrativeArea) int { return a.ID })},
+			{"states", ids(loc.States, func(s *models.State) int { return s.ID })},
+		}
+		for _, level := range stale {
+			_, err := tx.Exec(ctx, `
+				DELETE FROM `+level.table+`
+				WHERE country_code=$1 AND id <> ALL($2::BIGINT[])
+			`, loc.CountryCode, level.ids)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Admin: delete location
+func (st *LocationStore) Delete(ctx context.Context, code string) error {
+	_, err := st.pool.Exec(ctx, `DELETE FROM locations WHERE country_code=$1`, code)
+	if _, ok := violation(err, "23503"); ok {
+		return models.ErrLocationInUse
+	}
+	return err
+}
+
+func (st *LocationStore) ListStates(ctx context.Context, code string) ([]*models.State, error) {
+	if err := st.exists(ctx, `SELECT 1 FROM locations WHERE country_code=$1`, code); err != nil {
+		return nil, err
+	}
+	return collect[models.State](ctx, st.pool, `
+		SELECT id, name FROM states WHERE country_code=$1 ORDER BY name
+	`, code)
+}
+
+func (st *LocationStore) ListAdministrativeAreas(ctx context.Context, code string, stateID int) ([]*models.AdministrativeArea, error) {
+	if err := st.exists(ctx, `SELECT 1 FROM states WHERE country_code=$1 AND id=$2`, code, stateID); err != nil {
+		return nil, err
+	}
+	return collect[models.AdministrativeArea](ctx, st.pool, `
+		SELECT id, state_id, name FROM administrative_areas
+		WHERE country_code=$1 AND state_id=$2
+		ORDER BY name
+	`, code, stateID)
+}
+
+func (st *LocationStore) ListSubAdministrativeAreas(ctx context.Context, code string, areaID int) ([]*models.SubAdministrativeArea, error) {
+	if err := st.exists(ctx, `SELECT 1 FROM administrative_areas WHERE country_code=$1 AND id=$2`, code, areaID); err != nil {
+		return nil, err
+	}
+	return collect[models.SubAdministrativeArea](ctx, st.pool, `
+		SELECT id, administrative_area_id, name, latitude, longitude
+		FROM sub_administrative_areas
+		WHERE country_code=$1 AND administrative_area_id=$2
+		ORDER BY name
+	`, code, areaID)
+}
+
+// exists returns models.ErrNotFound when query yields no row.
+func (st *LocationStore) exists(ctx context.Context, query string, args ...any) error {
+	var one int
+	return notFound(st.pool.QueryRow(ctx, query, args...).Scan(&one))
+}
+
+// withTx runs fn in a transaction and maps foreign key violations: a missing
+// parent is an invalid hierarchy, a row still used by services is in use.
+func (st *LocationStore) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(tx)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if constraint, ok := violation(err, "23503"); ok {
+		if strings.HasPrefix(constraint, "fk_services_") {
+			return models.ErrLocationInUse
+		}
+		return models.ErrInvalidLocation
+	}
+	return err
+}
+
+// upsertHierarchy writes every level of loc, parents first.
+func upsertHierarchy(ctx context.Context, tx pgx.Tx, loc *models.Location) error {
+	var stateIDs, areaIDs, subAreaIDs, areaStates, subAreaAreas []int
+	var stateNames, areaNames, subAreaNames []string
+	var subAreaLats, subAreaLngs []*float64
+	for _, s := range loc.States {
+		stateIDs, stateNames = append(stateIDs, s.ID), append(stateNames, s.Name)
+	}
+	for _, a := range loc.AdministrativeAreas {
+		areaIDs, areaStates, areaNames = append(areaIDs, a.ID), append(areaStates, a.StateID), append(areaNames, a.Name)
+	}
+	for _, a := range loc.SubAdministrativeAreas {
+		subAreaIDs, subAreaAreas, subAreaNames = append(subAreaIDs, a.ID), append(subAreaAreas, a.AdministrativeAreaID), append(subAreaNames, a.Name)
+		subAreaLats, subAreaLngs = append(subAreaLats, a.Latitude), append(subAreaLngs, a.Longitude)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO states (country_code, id, name)
+		SELECT $1, * FROM unnest($2::BIGINT[], $3::TEXT[])
+		ON CONFLICT (country_code, id) DO UPDATE SET name=EXCLUDED.name
+	`, loc.CountryCode, stateIDs, stateNames); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO administrative_areas (country_code, id, state_id, name)
+		SELECT $1, * FROM unnest($2::BIGINT[], $3::BIGINT[], $4::TEXT[])
+		ON CONFLICT (country_code, id) DO UPDATE SET state_id=EXCLUDED.state_id, name=EXCLUDED.name
+	`, loc.CountryCode, areaIDs, areaStates, areaNames); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO sub_administrative_areas (country_code, id, administrative_area_id, name, latitude, longitude)
+		SELECT $1, * FROM unnest($2::BIGINT[], $3::BIGINT[], $4::TEXT[], $5::FLOAT8[], $6::FLOAT8[])
+		ON CONFLICT (country_code, id) DO UPDATE SET
+			administrative_area_id=EXCLUDED.administrative_area_id, name=EXCLUDED.name,
+			latitude=EXCLUDED.latitude, longitude=EXCLUDED.longitude
+	`, loc.CountryCode, subAreaIDs, subAreaAreas, subAreaNames, subAreaLats, subAreaLngs)
+	return err
+}
+
+// collect scans every row into a T by column position.
+func collect[T any](ctx context.Context, pool *pgxpool.Pool, query string, args ...any) ([]*T, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[T])
+}
+
+func ids[T any](items []*T, id func(*T) int) []int {
+	out := make([]int, 0, len(items))
+	for _, item := range items {
+		out = append(out, id(item))
+	}
+	return out
+}
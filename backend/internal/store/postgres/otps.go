@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OTPStore struct {
+	pool *pgxpool.Pool
+}
+
+// A resend inside the window keeps its counts, so asking for a new code does
+// not buy more guesses. The WHERE refuses it once either count is used up.
+func (st *OTPStore) Create(ctx context.Context, otp *models.PhoneOTP, limits models.OTPLimits) error {
+	err := st.pool.QueryRow(ctx, `
+		INSERT INTO phone_otps (phone, code_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (phone) DO UPDATE
+		SET code_hash = EXCLUDED.code_hash, expires_at = EXCLUDED.expires_at,
+		    attempts = 0, created_at = NOW(),
+		    sends = CASE WHEN phone_otps.window_started_at <= $4 THEN 1 ELSE phone_otps.sends + 1 END,
+		    failures = CASE WHEN phone_otps.window_started_at <= $4 THEN 0 ELSE phone_otps.failures END,
+		    window_started_at = CASE WHEN phone_otps.window_started_at <= $4 THEN NOW() ELSE phone_otps.window_started_at END
+		WHERE phone_otps.window_started_at <= $4
+		   OR (phone_otps.sends < $5 AND phone_otps.failures < $6)
+		RETURNING attempts, sends, failures, window_started_at, created_at
+	`, otp.Phone, otp.CodeHash, otp.ExpiresAt, time.Now().Add(-limits.Window), limits.Sends, limits.Failures,
+	).Scan(&otp.Attempts, &otp.Sends, &otp.Failures, &otp.WindowStart, &otp.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrOTPLocked
+	}
+	return err
+}
+
+func (st *OTPStore) Get(ctx context.Context, phone string) (*models.PhoneOTP, error) {
+	otp := &models.PhoneOTP{}
+	err := st.pool.QueryRow(ctx, `
+		SELECT phone, code_hash, attempts, sends, failures, window_started_at, expires_at, created_at
+		FROM phone_otps
+		WHERE phone=$1
+	`, phone).Scan(&otp.Phone, &otp.CodeHash, &otp.Attempts, &otp.Sends, &otp.Failures, &otp.WindowStart, &otp.ExpiresAt, &otp.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return otp, nil
+}
+
+// The row lock makes concurrent guesses for the same phone count one by one,
+// so the attempt limit cannot be raced.
+func (st *OTPStore) Consume(ctx context.Context, phone, codeHash string, limits models.OTPLimits) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var stored string
+	var attempts, failures int
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT code_hash, attempts, failures, expires_at
+		FROM phone_otps
+		WHERE phone=$1
+		FOR UPDATE
+	`, phone).Scan(&stored, &attempts, &failures, &expiresAt)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if !expiresAt.After(time.Now()) {
+		return models.ErrNotFound
+	}
+	if attempts >= limits.Attempts || failures >= limits.Failures {
+		return models.ErrOTPLocked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(codeHash)) != 1 {
+		if _, err := tx.Exec(ctx, `UPDATE phone_otps SET attempts = attempts + 1, failures = failures + 1 WHERE phone=$1`, phone); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		if attempts+1 >= limits.Attempts || failures+1 >= limits.Failures {
+			return models.ErrOTPLocked
+		}
+		return models.ErrOTPMismatch
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM phone_otps WHERE phone=$1`, phone); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
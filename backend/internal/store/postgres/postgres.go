@@ -0,0 +1,98 @@
+// Package postgres implements the models stores on top of a pgx pool.
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// New returns every store backed by pool.
+func New(pool *pgxpool.Pool) models.Stores {
+	return models.Stores{
+		Users:         &UserStore{pool: pool},
+		Categories:    &CategoryStore{pool: pool},
+		SubCategories: &SubCategoryStore{pool: pool},
+		Locations:     &LocationStore{pool: pool},
+		Services:      &ServiceStore{pool: pool},
+		Bookings:      &BookingStore{pool: pool},
+		Ratings:       &RatingStore{pool: pool},
+		OTPs:          &OTPStore{pool: pool},
+		Sessions:      &SessionStore{pool: pool},
+		Audit:         &AuditStore{pool: pool},
+	}
+}
+
+// notFound maps pgx's missing-row error to models.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+// violation reports whether err is a Postgres error with the given SQLSTATE
+// and returns the constraint it names.
+func violation(err error, code string) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == code {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// conditions builds a parameterised WHERE clause for optional filters.
+type conditions struct {
+	where []string
+	args  []any
+}
+
+// add appends cond, with each "?" replaced by the placeholder for arg.
+func (c *conditions) add(cond string, arg any) {
+	c.where = append(c.where, strings.ReplaceAll(cond, "?", c.arg(arg)))
+}
+
+// arg appends a bare argument and returns its placeholder.
+func (c *conditions) arg(v any) string {
+	c.args = append(c.args, v)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+// clause returns " WHERE ..." joining every condition, or "" when there are none.
+func (c *conditions) clause() string {
+	if len(c.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.where, " AND ")
+}
+
+// escapeLike escapes the LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// countBy collects (key, count) rows into a map.
+func countBy(ctx context.Context, pool *pgxpool.Pool, query string, args ...any) (map[string]int, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingStore struct {
+	pool *pgxpool.Pool
+}
+
+const ratingColumns = `id, COALESCE(booking_id, 0), user_id, provider_id, service_id, rating, COALESCE(comment, ''), created_at`
+
+func scanRating(row pgx.Row) (*models.Rating, error) {
+	rt := &models.Rating{}
+	err := row.Scan(
+		&rt.ID, &rt.BookingID, &rt.UserID, &rt.ProviderID, &rt.ServiceID,
+		&rt.Rating, &rt.Comment, &rt.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+func (st *RatingStore) Create(ctx context.Context, rt *models.Rating) error {
+	return st.withProviderTx(ctx, rt.ProviderID, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO ratings (booking_id, user_id, provider_id, service_id, rating, comment)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+			RETURNING id, created_at
+		`, rt.BookingID, rt.UserID, rt.ProviderID, rt.ServiceID, rt.Rating, rt.Comment).Scan(&rt.ID, &rt.CreatedAt)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.ErrRatingExists
+		}
+		return err
+	})
+}
+
+func (st *RatingStore) Update(ctx context.Context, rt *models.Rating) error {
+	return st.withProviderTx(ctx, rt.ProviderID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE ratings
+			SET rating=$1, comment=NULLIF($2, '')
+			WHERE id=$3
+		`, rt.Rating, rt.Comment, rt.ID)
+		return err
+	})
+}
+
+func (st *RatingStore) Delete(ctx context.Context, rt *models.Rating) error {
+	return st.withProviderTx(ctx, rt.ProviderID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `DELETE FROM ratings WHERE id=$1`, rt.ID)
+		return err
+	})
+}
+
+func (st *RatingStore) GetByID(ctx context.Context, id int64) (*models.Rating, error) {
+	rt, err := scanRating(st.pool.QueryRow(ctx, `
+		SELECT `+ratingColumns+`
+		FROM ratings
+		WHERE id=$1
+	`, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return rt, nil
+}
+
+func (st *RatingStore) GetByServiceID(ctx context.Context, serviceID int64) ([]*models.Rating, error) {
+	return st.query(ctx, `
+		SELECT `+ratingColumns+`
+		FROM ratings
+		WHERE service_id=$1
+		ORDER BY created_at DESC
+	`, serviceID)
+}
+
+func (st *RatingStore) GetByProviderID(ctx context.Context, providerID int64) ([]*models.Rating, error) {
+	return st.query(ctx, `
+		SELECT `+ratingColumns+`
+		FROM ratings
+		WHERE provider_id=$1
+		ORDER BY created_at DESC
+	`, providerID)
+}
+
+func (st *RatingStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Rating, error) {
+	return st.query(ctx, `
+		SELECT `+ratingColumns+`
+		FROM ratings
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`, userID)
+}
+
+// withProviderTx runs fn in a transaction that holds the provider's user row
+// lock, then recomputes users.rating_avg and rating_count from the ratings
+// table. Locking the provider serialises concurrent writes for the same
+// provider so the aggregate is always computed from committed rows.
+func (st *RatingStore) withProviderTx(ctx context.Context, providerID int64, fn func(tx pgx.Tx) error) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id=$1 FOR UPDATE`, providerID); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET rating_avg = COALESCE((SELECT ROUND(AVG(rating), 2) FROM ratings WHERE provider_id=$1), 0),
+		    rating_count = (SELECT COUNT(*) FROM ratings WHERE provider_id=$1)
+		WHERE id=$1
+	`, providerID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (st *RatingStore) query(ctx context.Context, query string, args ...any) ([]*models.Rating, error) {
+	rows, err := st.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []*models.Rating
+	for rows.Next() {
+		rt, err := scanRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
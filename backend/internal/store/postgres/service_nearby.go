@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func (st *ServiceStore) Nearby(ctx context.Context, f models.NearbyFilter) ([]*models.Service, int, error) {
+	c := conditions{where: []string{`services.active = TRUE`, `services.` + ownerVisible}}
+	if f.CategoryID != 0 {
+		c.add(`services.category_id = ?`, f.CategoryID)
+	}
+	if f.SubcategoryID != 0 {
+		c.add(`services.subcategory_id = ?`, f.SubcategoryID)
+	}
+	filters := strings.Join(c.where, " AND ")
+
+	// Each branch boxes its own point so services with coordinates can use
+	// idx_services_coordinates; the rest are placed at their sub-area's centroid
+	minLat, maxLat, minLng, maxLng, wraps := models.BoundingBox(f.Latitude, f.Longitude, f.RadiusKM)
+	latMin, latMax, lngMin, lngMax := c.arg(minLat), c.arg(maxLat), c.arg(minLng), c.arg(maxLng)
+	box := func(table string) string {
+		cond := table + `.latitude BETWEEN ` + latMin + ` AND ` + latMax
+		if !wraps {
+			cond += ` AND ` + table + `.longitude BETWEEN ` + lngMin + ` AND ` + lngMax
+		}
+		return cond
+	}
+
+	lat, lng := c.arg(f.Latitude), c.arg(f.Longitude)
+	distance := func(table string) string {
+		return `2 * ` + strconv.FormatFloat(models.EarthRadiusKM, 'f', -1, 64) + ` * asin(LEAST(1, sqrt(
+			power(sin(radians(` + table + `.latitude - ` + lat + `) / 2), 2) +
+			cos(radians(` + lat + `)) * cos(radians(` + table + `.latitude)) *
+			power(sin(radians(` + table + `.longitude - ` + lng + `) / 2), 2)
+		)))`
+	}
+
+	near := `
+		SELECT services.*, ` + distance("services") + ` AS distance_km
+		FROM services
+		WHERE ` + filters + ` AND ` + box("services") + `
+		UNION ALL
+		SELECT services.*, ` + distance("sa") + ` AS distance_km
+		FROM services
+		JOIN sub_administrative_areas sa
+			ON sa.country_code = services.country_code AND sa.id = services.sub_administrative_area_id
+		WHERE ` + filters + ` AND services.latitude IS NULL AND ` + box("sa")
+	within := ` WHERE distance_km <= ` + c.arg(f.RadiusKM)
+	filterArgs := c.args
+
+	rows, err := st.pool.Query(ctx, `
+		SELECT `+serviceColumns+`, distance_km, COUNT(*) OVER ()
+		FROM (`+near+`) near`+within+`
+		ORDER BY distance_km, id
+		LIMIT `+c.arg(f.Limit)+` OFFSET `+c.arg(f.Offset), c.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	services := []*models.Service{}
+	var total int
+	for rows.Next() {
+		var distance float64
+		s, err := scanService(rows, &distance, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		distance = math.Round(distance*1000) / 1000
+		s.DistanceKM = &distance
+		services = append(services, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// A page past the last row carries no window count
+	if len(services) == 0 && f.Offset > 0 {
+		if err := st.pool.QueryRow(ctx, `SELECT COUNT(*) FROM (`+near+`) near`+within, filterArgs...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return services, total, nil
+}
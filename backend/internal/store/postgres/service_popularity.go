@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+)
+
+// titleKey folds the case and spacing differences titles count as one under.
+const titleKey = `lower(regexp_replace(btrim(title), '\s+', ' ', 'g'))`
+
+func (st *ServiceStore) Popularity(ctx context.Context, titles int) (*models.ServicePopularity, error) {
+	p := models.NewServicePopularity()
+	visible := ` WHERE active = TRUE AND ` + ownerVisible
+
+	rows, err := st.pool.Query(ctx, `
+		SELECT category_id, subcategory_id, COUNT(*)
+		FROM services`+visible+`
+		GROUP BY 1, 2
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var categoryID, subcategoryID int64
+		var n int
+		if err := rows.Scan(&categoryID, &subcategoryID, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.AddCategory(categoryID, subcategoryID, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = st.pool.Query(ctx, `
+		SELECT country_code, state_id, administrative_area_id, sub_administrative_area_id, COUNT(*)
+		FROM services`+visible+`
+		GROUP BY 1, 2, 3, 4
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var countryCode string
+		var stateID, areaID, subAreaID, n int
+		if err := rows.Scan(&countryCode, &stateID, &areaID, &subAreaID, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.AddPlace(countryCode, stateID, areaID, subAreaID, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = st.pool.Query(ctx, `
+		SELECT min(regexp_replace(btrim(title), '\s+', ' ', 'g')), COUNT(*)
+		FROM services`+visible+` AND `+titleKey+` <> ''
+		GROUP BY `+titleKey+`
+		ORDER BY 2 DESC, 1
+		LIMIT $1
+	`, titles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.TitleCount
+		if err := rows.Scan(&t.Title, &t.Count); err != nil {
+			return nil, err
+		}
+		p.Titles = append(p.Titles, t)
+	}
+	return p, rows.Err()
+}
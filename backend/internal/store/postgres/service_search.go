@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"backend/internal/search"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// providerRating is the average rating of a service's provider.
+const providerRating = `(SELECT rating_avg FROM users WHERE users.id = services.user_id)`
+
+// headlineText is the HTML-escaped text snippets are cut from.
+const headlineText = `replace(replace(replace(
+	concat_ws(' · ', NULLIF(title, ''), NULLIF(caption, ''), NULLIF(area, ''), NULLIF(description, '')),
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// serviceOrders is the ORDER BY of each search order. id breaks ties so the
+// order is total, which keyset pagination needs.
+var serviceOrders = map[string]string{
+	models.ServiceSortNewest: `created_at DESC, id DESC`,
+	models.ServiceSortRating: providerRating + ` DESC, id DESC`,
+	models.ServiceSortPrice:  `price_amount ASC NULLS LAST, id ASC`,
+}
+
+// textQuery is the SQL ranking and highlighting matches of a search query.
+// Without a query every service ranks 0 and has no snippet.
+type textQuery struct {
+	rank, snippet string
+}
+
+func (st *ServiceStore) Search(ctx context.Context, f models.ServiceFilter) (*models.ServicePage, error) {
+	var cursor *models.ServiceCursor
+	if f.Cursor != "" {
+		var err error
+		if cursor, err = models.DecodeServiceCursor(f.Cursor, f.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	c, text := serviceConditions(f)
+	page := &models.ServicePage{Services: []*models.Service{}}
+	if err := st.pool.QueryRow(ctx, `SELECT COUNT(*) FROM services`+c.clause(), c.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	order := serviceOrders[f.Sort]
+	if f.Sort == models.ServiceSortRelevance {
+		order = text.rank + ` DESC, id DESC`
+	}
+	if cursor != nil {
+		c.where = append(c.where, afterCursor(&c, cursor, text))
+	}
+	rows, err := st.pool.Query(ctx, `
+		SELECT `+serviceColumns+`, `+providerRating+`, `+text.rank+`, `+text.snippet+`
+		FROM services`+c.clause()+`
+		ORDER BY `+order+`
+		LIMIT `+c.arg(f.Limit+1), c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []float64
+	for rows.Next() {
+		var rating, rank float64
+		var snippet string
+		s, err := scanService(rows, &rating, &rank, &snippet)
+		if err != nil {
+			return nil, err
+		}
+		s.Rank, s.Snippet = rank, snippet
+		page.Services = append(page.Services, s)
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// One row past the limit tells whether another page follows
+	if len(page.Services) > f.Limit {
+		page.Services = page.Services[:f.Limit]
+		last := page.Services[f.Limit-1]
+		page.NextCursor = models.NewServiceCursor(f.Sort, last, ratings[f.Limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// facetCount groups the services matching a facet's filter by key, an
+// expression over services and the joins in from.
+type facetCount struct {
+	facet     string
+	dest      *map[string]int
+	key, from string
+}
+
+func (st *ServiceStore) Facets(ctx context.Context, f models.ServiceFilter) (*models.ServiceFacets, error) {
+	facets := &models.ServiceFacets{}
+	counts := []facetCount{
+		{models.ServiceFacetCategories, &facets.Categories, `category_id::TEXT`, ``},
+		{models.ServiceFacetSubcategories, &facets.Subcategories, `subcategory_id::TEXT`, ``},
+		{models.ServiceFacetDays, &facets.Days, `day`, `, unnest(days) AS day`},
+	}
+	if f.CountryCode != "" {
+		counts = append(counts,
+			facetCount{models.ServiceFacetStates, &facets.States, `state_id::TEXT`, ``},
+			facetCount{models.ServiceFacetAdministrativeAreas, &facets.AdministrativeAreas, `administrative_area_id::TEXT`, ``},
+		)
+	}
+
+	for _, count := range counts {
+		c, _ := serviceConditions(f.ForFacet(count.facet))
+		m, err := countBy(ctx, st.pool, `
+			SELECT `+count.key+`, COUNT(*)
+			FROM services`+count.from+c.clause()+`
+			GROUP BY 1
+		`, c.args...)
+		if err != nil {
+			return nil, err
+		}
+		*count.dest = m
+	}
+	return facets, nil
+}
+
+// serviceConditions filters services by f, leaving out the cursor. A query
+// matches the full-text document word for word, or the skeleton loosely.
+func serviceConditions(f models.ServiceFilter) (conditions, textQuery) {
+	c := conditions{where: []string{`active = TRUE`, ownerVisible}}
+	if f.CountryCode != "" {
+		c.add(`country_code = ?`, f.CountryCode)
+	}
+	if f.StateID != 0 {
+		c.add(`state_id = ?`, f.StateID)
+	}
+	if f.AdministrativeAreaID != 0 {
+		c.add(`administrative_area_id = ?`, f.AdministrativeAreaID)
+	}
+	if f.SubAdministrativeAreaID != 0 {
+		c.add(`sub_administrative_area_id = ?`, f.SubAdministrativeAreaID)
+	}
+	if f.CategoryID != 0 {
+		c.add(`category_id = ?`, f.CategoryID)
+	}
+	if f.SubcategoryID != 0 {
+		c.add(`subcategory_id = ?`, f.SubcategoryID)
+	}
+	if len(f.Days) > 0 {
+		c.add(`days @> ?`, f.Days)
+	}
+
+	text := textQuery{rank: `0::NUMERIC`, snippet: `''::TEXT`}
+	if f.Query == "" {
+		return c, text
+	}
+
+	tsq := `websearch_to_tsquery('simple', ` + c.arg(f.Query) + `)`
+	match := `search_document @@ ` + tsq
+	rank := `ts_rank_cd(search_document, ` + tsq + `) * 2`
+	if skeleton := search.Skeleton(f.Query); skeleton != "" {
+		p := c.arg(skeleton)
+		match = `(` + match + ` OR ` + p + ` <% search_skeleton)`
+		rank += ` + word_similarity(` + p + `, COALESCE(search_skeleton, ''))`
+	}
+	c.where = append(c.where, match)
+
+	text.rank = `ROUND((` + rank + `)::NUMERIC, 6)`
+	text.snippet = `ts_headline('simple', ` + headlineText + `, ` + tsq + `,
+		'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=8')`
+	return c, text
+}
+
+// afterCursor restricts a search to the services ordered after cur.
+func afterCursor(c *conditions, cur *models.ServiceCursor, text textQuery) string {
+	switch cur.Sort {
+	case models.ServiceSortRating:
+		return fmt.Sprintf(`(%s, id) < (%s::NUMERIC, %s)`, providerRating, c.arg(cur.Key), c.arg(cur.ID))
+	case models.ServiceSortRelevance:
+		return fmt.Sprintf(`(%s, id) < (%s::NUMERIC, %s)`, text.rank, c.arg(cur.Key), c.arg(cur.ID))
+	case models.ServiceSortPrice:
+		if cur.Key == "" {
+			return fmt.Sprintf(`(price_amount IS NULL AND id > %s)`, c.arg(cur.ID))
+		}
+		return fmt.Sprintf(`(price_amount IS NULL OR (price_amount, id) > (%s::NUMERIC, %s))`, c.arg(cur.Key), c.arg(cur.ID))
+	default:
+		return fmt.Sprintf(`(created_at, id) < (%s::TIMESTAMPTZ, %s)`, c.arg(cur.Key), c.arg(cur.ID))
+	}
+}
+
+// backfillBatch is how many services BackfillSearchSkeletons reads and
+// updates per round trip.
+const backfillBatch = 500
+
+// BackfillSearchSkeletons fills in search_skeleton for services written
+// before it existed and returns how many were updated. It needs migration
+// 0011 applied.
+func BackfillSearchSkeletons(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	var total int
+	var after int64
+	for {
+		rows, err := pool.Query(ctx, `
+			SELECT id, title, caption, area, description, features
+			FROM services
+			WHERE search_skeleton IS NULL AND id > $1
+			ORDER BY id
+			LIMIT $2
+		`, after, backfillBatch)
+		if err != nil {
+			return total, err
+		}
+
+		var ids []int64
+		var skeletons []string
+		for rows.Next() {
+			s := &models.Service{}
+			if err := rows.Scan(&s.ID, &s.Title, &s.Caption, &s.Area, &s.Description, &s.Features); err != nil {
+				rows.Close()
+				return total, err
+			}
+			ids = append(ids, s.ID)
+			skeletons = append(skeletons, search.Skeleton(search.ServiceText(s)))
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		// A concurrent write may have set the skeleton meanwhile; keep it
+		_, err = pool.Exec(ctx, `
+			UPDATE services SET search_skeleton = b.skeleton
+			FROM unnest($1::BIGINT[], $2::TEXT[]) AS b(id, skeleton)
+			WHERE services.id = b.id AND services.search_skeleton IS NULL
+		`, ids, skeletons)
+		if err != nil {
+			return total, err
+		}
+		total += len(ids)
+		after = ids[len(ids)-1]
+	}
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"backend/internal/search"
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ServiceStore struct {
+	pool *pgxpool.Pool
+}
+
+const serviceColumns = `
+	id, active, user_id, country_code, category_id, subcategory_id,
+	state_id, administrative_area_id, sub_administrative_area_id,
+	area, title, caption, description, price, price_amount,
+	features, hours, days,
+	page_name, page_link, messenger_name, messenger_link,
+	latitude, longitude, created_at`
+
+// ownerVisible hides the services of suspended and banned users from listings.
+const ownerVisible = `user_id NOT IN (SELECT id FROM users WHERE status IN ('suspended', 'banned'))`
+
+func scanService(row pgx.Row, extra ...any) (*models.Service, error) {
+	s := &models.Service{}
+	dest := []any{
+		&s.ID, &s.Active, &s.UserID, &s.CountryCode, &s.CategoryID, &s.SubcategoryID,
+		&s.StateID, &s.AdministrativeAreaID, &s.SubAdministrativeAreaID,
+		&s.Area, &s.Title, &s.Caption, &s.Description, &s.Price, &s.PriceAmount,
+		&s.Features, &s.Hours, &s.Days,
+		&s.PageName, &s.PageLink, &s.MessengerName, &s.MessengerLink,
+		&s.Latitude, &s.Longitude, &s.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (st *ServiceStore) Create(ctx context.Context, s *models.Service) error {
+	s.PriceAmount = models.ParsePrice(s.Price)
+	err := st.pool.QueryRow(ctx, `
+		INSERT INTO services (
+			active, user_id, country_code, category_id, subcategory_id,
+			state_id, administrative_area_id, sub_administrative_area_id,
+			area, title, caption, description, price, price_amount,
+			features, hours, days,
+			page_name, page_link, messenger_name, messenger_link,
+			latitude, longitude, search_skeleton
+		) VALUES (
+			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24
+		)
+		RETURNING id, created_at
+	`, s.Active, s.UserID, s.CountryCode, s.CategoryID, s.SubcategoryID,
+		s.StateID, s.AdministrativeAreaID, s.SubAdministrativeAreaID,
+		s.Area, s.Title, s.Caption, s.Description, s.Price, s.PriceAmount,
+		s.Features, s.Hours, s.Days,
+		s.PageName, s.PageLink, s.MessengerName, s.MessengerLink,
+		s.Latitude, s.Longitude, search.Skeleton(search.ServiceText(s)),
+	).Scan(&s.ID, &s.CreatedAt)
+	return locationError(err)
+}
+
+func (st *ServiceStore) GetByID(ctx context.Context, id int64) (*models.Service, error) {
+	s, err := scanService(st.pool.QueryRow(ctx, `
+		SELECT `+serviceColumns+`
+		FROM services
+		WHERE id=$1
+	`, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return s, nil
+}
+
+func (st *ServiceStore) Update(ctx context.Context, s *models.Service) error {
+	s.PriceAmount = models.ParsePrice(s.Price)
+	_, err := st.pool.Exec(ctx, `
+		UPDATE services
+		SET active=$1, country_code=$2, category_id=$3, subcategory_id=$4,
+		    state_id=$5, administrative_area_id=$6, sub_administrative_area_id=$7,
+		    area=$8, title=$9, caption=$10, description=$11,
+		    price=$12, price_amount=$13, features=$14, hours=$15, days=$16,
+		    page_name=$17, page_link=$18, messenger_name=$19, messenger_link=$20,
+		    latitude=$21, longitude=$22, search_skeleton=$23
+		WHERE id=$24
+	`, s.Active, s.CountryCode, s.CategoryID, s.SubcategoryID,
+		s.StateID, s.AdministrativeAreaID, s.SubAdministrativeAreaID,
+		s.Area, s.Title, s.Caption, s.Description,
+		s.Price, s.PriceAmount, s.Features, s.Hours, s.Days,
+		s.PageName, s.PageLink, s.MessengerName, s.MessengerLink,
+		s.Latitude, s.Longitude, search.Skeleton(search.ServiceText(s)), s.ID,
+	)
+	return locationError(err)
+}
+
+// locationError maps a service pointing outside its country's hierarchy to
+// models.ErrInvalidLocation.
+func locationError(err error) error {
+	if constraint, ok := violation(err, "23503"); ok && strings.HasPrefix(constraint, "fk_services_") {
+		return models.ErrInvalidLocation
+	}
+	return err
+}
+
+func (st *ServiceStore) Delete(ctx context.Context, id int64) error {
+	_, err := st.pool.Exec(ctx, `DELETE FROM services WHERE id=$1`, id)
+	return err
+}
+
+func (st *ServiceStore) GetByUserID(ctx context.Context, userID int64) ([]*models.Service, error) {
+	return st.query(ctx, `
+		SELECT `+serviceColumns+`
+		FROM services
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`, userID)
+}
+
+func (st *ServiceStore) List(ctx context.Context) ([]*models.Service, error) {
+	return st.query(ctx, `
+		SELECT `+serviceColumns+`
+		FROM services
+		WHERE active=TRUE AND `+ownerVisible+`
+		ORDER BY created_at DESC
+	`)
+}
+
+func (st *ServiceStore) query(ctx context.Context, query string, args ...any) ([]*models.Service, error) {
+	rows, err := st.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*models.Service
+	for rows.Next() {
+		s, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func (st *ServiceStore) CountActiveByCountry(ctx context.Context) (map[string]int, error) {
+	return countBy(ctx, st.pool, `
+		SELECT country_code, COUNT(*)
+		FROM services
+		WHERE active=TRUE AND `+ownerVisible+`
+		GROUP BY country_code
+	`)
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionStore struct {
+	pool *pgxpool.Pool
+}
+
+const sessionColumns = `
+	id, user_id, COALESCE(device_name, ''), COALESCE(user_agent, ''), COALESCE(ip, ''),
+	created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row pgx.Row) (*models.Session, error) {
+	sess := &models.Session{}
+	err := row.Scan(
+		&sess.ID, &sess.UserID, &sess.DeviceName, &sess.UserAgent, &sess.IP,
+		&sess.CreatedAt, &sess.LastUsedAt, &sess.ExpiresAt, &sess.RevokedAt,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return sess, nil
+}
+
+func (st *SessionStore) Create(ctx context.Context, sess *models.Session, tokenHash string) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sessions (user_id, device_name, user_agent, ip, expires_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING id, created_at, last_used_at
+	`, sess.UserID, sess.DeviceName, sess.UserAgent, sess.IP, sess.ExpiresAt).Scan(&sess.ID, &sess.CreatedAt, &sess.LastUsedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id)
+		VALUES ($1, $2)
+	`, tokenHash, sess.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// The token row lock makes two concurrent refreshes with the same token
+// serialise: the second one sees used_at set and is treated as reuse.
+func (st *SessionStore) Rotate(ctx context.Context, tokenHash, newHash string) (*models.Session, error) {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var sessionID int64
+	var usedAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT session_id, used_at
+		FROM refresh_tokens
+		WHERE token_hash=$1
+		FOR UPDATE
+	`, tokenHash).Scan(&sessionID, &usedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if usedAt != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE sessions SET revoked_at=NOW()
+			WHERE id=$1 AND revoked_at IS NULL
+		`, sessionID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	sess, err := scanSession(tx.QueryRow(ctx, `
+		UPDATE sessions
+		SET last_used_at=NOW()
+		WHERE id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING `+sessionColumns, sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at=NOW() WHERE token_hash=$1`, tokenHash); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id)
+		VALUES ($1, $2)
+	`, newHash, sessionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+func (st *SessionStore) GetByID(ctx context.Context, id int64) (*models.Session, error) {
+	return scanSession(st.pool.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id=$1`, id))
+}
+
+func (st *SessionStore) ListActive(ctx context.Context, userID int64) ([]*models.Session, error) {
+	rows, err := st.pool.Query(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		sess, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (st *SessionStore) Revoke(ctx context.Context, userID, id int64) error {
+	tag, err := st.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL AND expires_at > NOW()
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (st *SessionStore) RevokeAll(ctx context.Context, userID int64) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at=NOW()
+		WHERE user_id=$1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SubCategoryStore struct {
+	pool *pgxpool.Pool
+}
+
+func (st *SubCategoryStore) Create(ctx context.Context, sc *models.SubCategory) error {
+	return st.pool.QueryRow(ctx, `
+		INSERT INTO sub_categories (category_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, sc.CategoryID, sc.Name, sc.Description).Scan(&sc.ID, &sc.CreatedAt)
+}
+
+func (st *SubCategoryStore) GetByID(ctx context.Context, id int64) (*models.SubCategory, error) {
+	sc := &models.SubCategory{}
+	err := st.pool.QueryRow(ctx, `
+		SELECT id, category_id, name, description, created_at
+		FROM sub_categories
+		WHERE id=$1
+	`, id).Scan(&sc.ID, &sc.CategoryID, &sc.Name, &sc.Description, &sc.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return sc, nil
+}
+
+func (st *SubCategoryStore) GetAll(ctx context.Context) ([]*models.SubCategory, error) {
+	rows, err := st.pool.Query(ctx, `
+		SELECT id, category_id, name, description, created_at
+		FROM sub_categories
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subCategories []*models.SubCategory
+	for rows.Next() {
+		sc := &models.SubCategory{}
+		if err := rows.Scan(&sc.ID, &sc.CategoryID, &sc.Name, &sc.Description, &sc.CreatedAt); err != nil {
+			return nil, err
+		}
+		subCategories = append(subCategories, sc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subCategories, nil
+}
+
+func (st *SubCategoryStore) Update(ctx context.Context, sc *models.SubCategory) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE sub_categories
+		SET category_id = $1,
+		    name = $2,
+		    description = $3
+		WHERE id = $4
+	`, sc.CategoryID, sc.Name, sc.Description, sc.ID)
+
+	return err
+}
+
+func (st *SubCategoryStore) Delete(ctx context.Context, id int64) error {
+	_, err := st.pool.Exec(ctx, `
+		DELETE FROM sub_categories
+		WHERE id=$1
+	`, id)
+	return err
+}
@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserStore struct {
+	pool *pgxpool.Pool
+}
+
+// Most user columns are nullable; coalesce them so they scan into plain strings.
+const userColumns = `
+	id, COALESCE(verified, FALSE), role, status,
+	COALESCE(status_reason, ''), status_changed_at, COALESCE(status_changed_by, 0),
+	COALESCE(name, ''), COALESCE(avatar, ''), COALESCE(bio, ''),
+	COALESCE(phone, ''), COALESCE(email, ''), COALESCE(password, ''),
+	COALESCE(reset_token, ''), reset_token_expiry,
+	COALESCE(verify_token, ''), verify_token_expiry,
+	COALESCE(google_id, ''), COALESCE(google_id_token, ''), COALESCE(google_access_token, ''),
+	COALESCE(fcm_token, ''),
+	rating_avg, rating_count, created_at`
+
+// scanUser reads userColumns followed by any extra selected columns into extra.
+func scanUser(row pgx.Row, extra ...any) (*models.User, error) {
+	u := &models.User{}
+	dest := []any{
+		&u.ID, &u.Verified, &u.Role, &u.Status,
+		&u.StatusReason, &u.StatusChangedAt, &u.StatusChangedBy,
+		&u.Name, &u.Avatar, &u.Bio,
+		&u.Phone, &u.Email, &u.Password,
+		&u.ResetToken, &u.ResetTokenExpiry,
+		&u.VerifyToken, &u.VerifyTokenExpiry,
+		&u.GoogleID, &u.GoogleIDToken, &u.GoogleAccessToken,
+		&u.FCMToken,
+		&u.RatingAvg, &u.RatingCount, &u.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, notFound(err)
+	}
+	return u, nil
+}
+
+func (st *UserStore) EnsureSuperAdmin(ctx context.Context, email, hashedPassword string) error {
+	var id int64
+	err := st.pool.QueryRow(ctx, `SELECT id FROM users WHERE role='superadmin'`).Scan(&id)
+	if err != nil {
+		_, err := st.pool.Exec(ctx, `
+			INSERT INTO users (email, password, role, status, verified)
+			VALUES ($1, $2, 'superadmin', 'active', TRUE)
+		`, email, hashedPassword)
+		return err
+	}
+
+	_, err = st.pool.Exec(ctx, `
+		UPDATE users
+		SET email=$1, password=$2, status='active'
+		WHERE id=$3
+	`, email, hashedPassword, id)
+	return err
+}
+
+func (st *UserStore) CreateWithGoogle(ctx context.Context, u *models.User) error {
+	_, err := st.pool.Exec(ctx, `
+		INSERT INTO users (email, google_id, name, avatar, verified)
+		VALUES ($1, $2, $3, $4, $5)
+	`, u.Email, u.GoogleID, u.Name, u.Avatar, u.Verified)
+	return err
+}
+
+func (st *UserStore) CreateWithEmail(ctx context.Context, u *models.User) error {
+	return st.pool.QueryRow(ctx, `
+		INSERT INTO users (email, password)
+		VALUES ($1, $2)
+		RETURNING id, role, status, created_at
+	`, u.Email, u.Password).Scan(&u.ID, &u.Role, &u.Status, &u.CreatedAt)
+}
+
+func (st *UserStore) CreateWithPhone(ctx context.Context, u *models.User) error {
+	return st.pool.QueryRow(ctx, `
+		INSERT INTO users (phone, verified)
+		VALUES ($1, $2)
+		RETURNING id, role, status, created_at
+	`, u.Phone, u.Verified).Scan(&u.ID, &u.Role, &u.Status, &u.CreatedAt)
+}
+
+func (st *UserStore) GetByID(ctx context.Context, userID int64) (*models.User, error) {
+	return scanUser(st.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id=$1`, userID))
+}
+
+func (st *UserStore) GetByGoogleID(ctx context.Context, googleID string) (*models.User, error) {
+	return scanUser(st.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE google_id=$1`, googleID))
+}
+
+func (st *UserStore) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return scanUser(st.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE phone=$1`, phone))
+}
+
+func (st *UserStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return scanUser(st.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email=$1`, email))
+}
+
+func (st *UserStore) LinkGoogle(ctx context.Context, u *models.User) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The CASEs and RETURNING see the row as it was before the update
+	var wasVerified bool
+	err = tx.QueryRow(ctx, `
+		UPDATE users u
+		SET google_id=$1, avatar=$2, verified=TRUE,
+		    password=CASE WHEN u.verified THEN u.password END,
+		    verify_token=NULL, verify_token_expiry=NULL
+		FROM users old
+		WHERE u.id=$3 AND old.id=u.id
+		RETURNING old.verified
+	`, u.GoogleID, u.Avatar, u.ID).Scan(&wasVerified)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if !wasVerified {
+		if _, err := tx.Exec(ctx, `
+			UPDATE sessions SET revoked_at=NOW()
+			WHERE user_id=$1 AND revoked_at IS NULL
+		`, u.ID); err != nil {
+			return err
+		}
+		u.Password = ""
+	}
+	u.Verified = true
+	return tx.Commit(ctx)
+}
+
+// Empty identifiers are written as NULL; the unique constraints would
+// otherwise allow only one user without a phone or email.
+func (st *UserStore) Update(ctx context.Context, u *models.User) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE users
+		SET phone=NULLIF($1, ''), name=$2, email=NULLIF($3, ''), avatar=$4, bio=$5,
+		    verified=$6, status=$7, google_id=NULLIF($8, '')
+		WHERE id=$9
+	`, u.Phone, u.Name, u.Email, u.Avatar, u.Bio,
+		u.Verified, u.Status, u.GoogleID, u.ID)
+	return err
+}
+
+func (st *UserStore) Search(ctx context.Context, f models.UserFilter) ([]*models.User, int, error) {
+	var c conditions
+	if f.Email != "" {
+		c.add(`email ILIKE '%' || ? || '%'`, escapeLike(f.Email))
+	}
+	if f.Phone != "" {
+		c.add(`phone LIKE '%' || ? || '%'`, escapeLike(f.Phone))
+	}
+	if f.Role != "" {
+		c.add(`role = ?`, f.Role)
+	}
+	if f.Status != "" {
+		c.add(`status = ?`, f.Status)
+	}
+	if f.CreatedFrom != nil {
+		c.add(`created_at >= ?`, *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		c.add(`created_at < ?`, *f.CreatedTo)
+	}
+
+	where, filterArgs := c.clause(), c.args
+	rows, err := st.pool.Query(ctx, `
+		SELECT `+userColumns+`, COUNT(*) OVER ()
+		FROM users`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT `+c.arg(f.Limit)+` OFFSET `+c.arg(f.Offset), c.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	var total int
+	for rows.Next() {
+		u, err := scanUser(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// A page past the last row carries no window count
+	if len(users) == 0 && f.Offset > 0 {
+		if err := st.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`+where, filterArgs...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return users, total, nil
+}
+
+func (st *UserStore) SetStatus(ctx context.Context, userID int64, status, reason string, changedBy int64) error {
+	tag, err := st.pool.Exec(ctx, `
+		UPDATE users
+		SET status=$1, status_reason=NULLIF($2, ''), status_changed_at=NOW(), status_changed_by=NULLIF($3, 0)
+		WHERE id=$4
+	`, status, reason, changedBy, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (st *UserStore) SetRole(ctx context.Context, userID int64, role string) error {
+	tag, err := st.pool.Exec(ctx, `UPDATE users SET role=$1 WHERE id=$2`, role, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (st *UserStore) SetResetToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE users
+		SET reset_token=$1, reset_token_expiry=$2
+		WHERE id=$3
+	`, tokenHash, expiry, userID)
+	return err
+}
+
+// Matching and clearing the token in one statement makes it single-use even
+// under concurrent requests.
+func (st *UserStore) ResetPassword(ctx context.Context, tokenHash, hashedPassword string) error {
+	tx, err := st.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE users
+		SET password=$1, reset_token=NULL, reset_token_expiry=NULL,
+		    verified=TRUE, verify_token=NULL, verify_token_expiry=NULL
+		WHERE reset_token=$2 AND reset_token_expiry > NOW()
+		RETURNING id
+	`, hashedPassword, tokenHash).Scan(&userID)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE sessions SET revoked_at=NOW()
+		WHERE user_id=$1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (st *UserStore) SetVerifyToken(ctx context.Context, userID int64, tokenHash string, expiry time.Time) error {
+	_, err := st.pool.Exec(ctx, `
+		UPDATE users
+		SET verify_token=$1, verify_token_expiry=$2
+		WHERE id=$3
+	`, tokenHash, expiry, userID)
+	return err
+}
+
+func (st *UserStore) VerifyEmail(ctx context.Context, tokenHash string) error {
+	tag, err := st.pool.Exec(ctx, `
+		UPDATE users
+		SET verified=TRUE, verify_token=NULL, verify_token_expiry=NULL
+		WHERE verify_token=$1 AND verify_token_expiry > NOW()
+	`, tokenHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
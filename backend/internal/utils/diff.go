@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// JSONDiff compares the JSON encodings of before and after field by field and
+// returns {"field": {"before": x, "after": y}} for every top-level field that
+// differs. A nil side, as on create or delete, counts as having no fields.
+func JSONDiff(before, after any) (map[string]any, error) {
+	b, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string]any{}
+	for k, bv := range b {
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(av, bv) {
+			diff[k] = map[string]any{"before": bv, "after": av}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = map[string]any{"before": nil, "after": av}
+		}
+	}
+	return diff, nil
+}
+
+func jsonFields(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// publicJWK encodes the verification key as an RFC 7517 JWK, or returns nil
+// for symmetric keys, which must never be published.
+func publicJWK(key *jwtKey) map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch pub := key.verify.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"kid": key.kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+			"n":   b64(pub.N.Bytes()),
+			"e":   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": key.kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+			"x":   b64(pub),
+		}
+	}
+	return nil
+}
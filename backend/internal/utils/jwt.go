@@ -1,70 +1,202 @@
 package utils
 
 import (
+	"crypto"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey []byte
-var accessTokenTTL = 15 * time.Minute
+// JWTOptions configures token signing. With KeyDir set, every <kid>.pem file
+// in it is loaded: ActiveKID signs new tokens and the rest, private or public,
+// only verify tokens issued before a rotation. Without KeyDir tokens are
+// signed with HS256 and Secret, which is meant for local development.
+type JWTOptions struct {
+	Secret     string
+	KeyDir     string
+	ActiveKID  string
+	Issuer     string
+	Audience   string
+	TTLMinutes int
+}
+
+type jwtKey struct {
+	kid    string
+	method jwt.SigningMethod
+	// sign is nil for keys that are only kept for verification
+	sign   any
+	verify any
+}
+
+var (
+	jwtKeys        map[string]*jwtKey
+	activeJWTKey   *jwtKey
+	jwtIssuer      string
+	jwtAudience    string
+	accessTokenTTL = 15 * time.Minute
+)
+
+func InitJWT(opts JWTOptions) error {
+	keys := map[string]*jwtKey{}
+	var active *jwtKey
+
+	if opts.KeyDir == "" {
+		if opts.Secret == "" {
+			return errors.New("jwt secret or key directory required")
+		}
+		active = &jwtKey{method: jwt.SigningMethodHS256, sign: []byte(opts.Secret), verify: []byte(opts.Secret)}
+		keys[""] = active
+	} else {
+		files, err := filepath.Glob(filepath.Join(opts.KeyDir, "*.pem"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			key, err := loadJWTKey(file)
+			if err != nil {
+				return fmt.Errorf("jwt key %s: %w", file, err)
+			}
+			keys[key.kid] = key
+		}
+
+		active = keys[opts.ActiveKID]
+		if active == nil {
+			return fmt.Errorf("active jwt key %q not found in %s", opts.ActiveKID, opts.KeyDir)
+		}
+		if active.sign == nil {
+			return fmt.Errorf("active jwt key %q has no private key", opts.ActiveKID)
+		}
+	}
+
+	jwtKeys, activeJWTKey = keys, active
+	jwtIssuer, jwtAudience = opts.Issuer, opts.Audience
+	if opts.TTLMinutes > 0 {
+		accessTokenTTL = time.Duration(opts.TTLMinutes) * time.Minute
+	}
+	return nil
+}
 
-func InitJWT(key string, ttlMinutes int) {
-	jwtKey = []byte(key)
-	if ttlMinutes > 0 {
-		accessTokenTTL = time.Duration(ttlMinutes) * time.Minute
+// loadJWTKey reads a PEM private or public key; the file name without .pem is its kid.
+func loadJWTKey(file string) (*jwtKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
 	}
+
+	key := &jwtKey{kid: strings.TrimSuffix(filepath.Base(file), ".pem")}
+
+	if priv, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		key.method, key.sign, key.verify = jwt.SigningMethodRS256, priv, priv.Public()
+	} else if priv, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		key.method, key.sign, key.verify = jwt.SigningMethodEdDSA, priv, priv.(crypto.Signer).Public()
+	} else if pub, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		key.method, key.verify = jwt.SigningMethodRS256, pub
+	} else if pub, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		key.method, key.verify = jwt.SigningMethodEdDSA, pub
+	} else {
+		return nil, errors.New("not an RSA or Ed25519 key")
+	}
+
+	return key, nil
 }
 
 type CustomClaims struct {
 	UserID int64  `json:"user_id"`
 	Phone  string `json:"phone"`
 	Role   string `json:"role"`
+	// SessionID ties the access token to the device session that issued it
+	SessionID int64 `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID int64, role string) (string, error) {
-	if len(jwtKey) == 0 {
+func GenerateJWT(userID int64, role string, sessionID int64) (string, error) {
+	if activeJWTKey == nil {
 		return "", errors.New("jwt key not initialized")
 	}
 
 	claims := &CustomClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtIssuer,
+			Subject:   fmt.Sprint(userID),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if jwtAudience != "" {
+		claims.Audience = jwt.ClaimStrings{jwtAudience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	token := jwt.NewWithClaims(activeJWTKey.method, claims)
+	if activeJWTKey.kid != "" {
+		token.Header["kid"] = activeJWTKey.kid
+	}
+	return token.SignedString(activeJWTKey.sign)
 }
 
-func VerifyJWT(tokenStr string) (userID int64, role string, err error) {
-	if len(jwtKey) == 0 {
-		return 0, "", errors.New("jwt key not initialized")
+func VerifyJWT(tokenStr string) (*CustomClaims, error) {
+	if activeJWTKey == nil {
+		return nil, errors.New("jwt key not initialized")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if jwtIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(jwtIssuer))
+	}
+	if jwtAudience != "" {
+		opts = append(opts, jwt.WithAudience(jwtAudience))
 	}
 
 	token, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		key := jwtKeys[kid]
+		if key == nil {
+			return nil, errors.New("unknown signing key")
+		}
+		// The key decides the algorithm, never the token header
+		if t.Method.Alg() != key.method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtKey, nil
-	})
+		return key.verify, nil
+	}, opts...)
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*CustomClaims)
 	if !ok {
-		return 0, "", errors.New("invalid claims type")
+		return nil, errors.New("invalid claims type")
 	}
 
 	if claims.UserID == 0 {
-		return 0, "", errors.New("invalid user claims")
+		return nil, errors.New("invalid user claims")
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public halves of every asymmetric key, active and retiring,
+// in JSON Web Key Set form. It is empty when tokens are signed with a secret.
+func JWKS() map[string]any {
+	kids := make([]string, 0, len(jwtKeys))
+	for kid := range jwtKeys {
+		kids = append(kids, kid)
 	}
+	sort.Strings(kids)
 
-	return claims.UserID, claims.Role, nil
+	keys := []map[string]string{}
+	for _, kid := range kids {
+		if jwk := publicJWK(jwtKeys[kid]); jwk != nil {
+			keys = append(keys, jwk)
+		}
+	}
+	return map[string]any{"keys": keys}
 }
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Bangladeshi mobile numbers: 01 followed by an operator digit 3-9 and eight more digits.
+var bdMobile = regexp.MustCompile(`^01[3-9]\d{8}$`)
+
+// NormalizeBDPhone accepts a Bangladeshi mobile number in the usual local or
+// international spellings (01712-345678, 8801712345678, +880 1712 345678,
+// 00880...) and returns it in E.164 form, +8801712345678.
+func NormalizeBDPhone(phone string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(digits, "+880"):
+		digits = "0" + strings.TrimPrefix(digits, "+880")
+	case strings.HasPrefix(digits, "00880"):
+		digits = "0" + strings.TrimPrefix(digits, "00880")
+	case strings.HasPrefix(digits, "880"):
+		digits = "0" + strings.TrimPrefix(digits, "880")
+	}
+
+	if !bdMobile.MatchString(digits) {
+		return "", errors.New("invalid Bangladeshi mobile number")
+	}
+
+	return "+88" + digits, nil
+}
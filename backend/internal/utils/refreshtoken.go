@@ -6,7 +6,7 @@ import (
 	"time"
 )
 
-var refreshTokenTTL time.Duration
+var refreshTokenTTL = 30 * 24 * time.Hour
 
 func InitRefreshTokenTTL(days int) {
 	if days > 0 {
@@ -16,6 +16,11 @@ func InitRefreshTokenTTL(days int) {
 	}
 }
 
+// RefreshTokenExpiry returns when a refresh token issued now stops being accepted.
+func RefreshTokenExpiry() time.Time {
+	return time.Now().Add(refreshTokenTTL)
+}
+
 func GenerateRefreshToken() (string, error) {
 	b := make([]byte, 64)
 	if _, err := rand.Read(b); err != nil {
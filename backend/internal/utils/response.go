@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 type APIResponse struct {
@@ -27,3 +30,65 @@ func JSON(w http.ResponseWriter, status int, success bool, message string, data
 		http.Error(w, `{"status":500,"success":false,"message":"Internal Server Error"}`, http.StatusInternalServerError)
 	}
 }
+
+// JSONCached writes a successful response like JSON, tagged with an ETag
+// derived from the body. Clients may keep it but must revalidate, and a
+// request whose If-None-Match still holds the tag gets 304 without a body.
+func JSONCached(w http.ResponseWriter, r *http.Request, message string, data any) {
+	c, err := NewCachedJSON(message, data)
+	if err != nil {
+		http.Error(w, `{"status":500,"success":false,"message":"Internal Server Error"}`, http.StatusInternalServerError)
+		return
+	}
+	c.Write(w, r)
+}
+
+// CachedJSON is a successful response encoded once, with its ETag, so it can
+// be kept and served many times.
+type CachedJSON struct {
+	body []byte
+	etag string
+}
+
+func NewCachedJSON(message string, data any) (*CachedJSON, error) {
+	body, err := json.Marshal(APIResponse{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	return &CachedJSON{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}, nil
+}
+
+// Write answers r as JSONCached does.
+func (c *CachedJSON) Write(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("ETag", c.etag)
+	w.Header().Set("Cache-Control", "public, no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), c.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(c.body)
+}
+
+// etagMatches reports whether an If-None-Match header names etag, comparing
+// weakly as RFC 9110 requires for this header.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
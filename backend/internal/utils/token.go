@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateToken returns a URL-safe token made of n random bytes
+func GenerateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 of a token. Single-use tokens are stored
+// hashed so a database leak does not hand out working links.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HMACToken returns the hex HMAC-SHA256 of token under key. Use it instead of
+// HashToken for secrets from a small space, such as OTP codes, which a plain
+// hash would not protect against enumeration.
+func HMACToken(key, token string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateOTP returns a random numeric code of the given length.
+func GenerateOTP(digits int) (string, error) {
+	b := make([]byte, digits)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 250 is the largest multiple of 10 below 256; redraw above it to avoid bias
+		for b[i] >= 250 {
+			if _, err := rand.Read(b[i : i+1]); err != nil {
+				return "", err
+			}
+		}
+		b[i] = '0' + b[i]%10
+	}
+	return string(b), nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// NormalizeEmail trims and lower-cases an email address and rejects anything
+// that is not a bare address fitting the users.email column.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 64 {
+		return "", errors.New("invalid email address")
+	}
+
+	return email, nil
+}
+
+// ValidatePassword enforces the password rules for registration and reset.
+// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+// rather than silently truncated.
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return errors.New("password must be at most 72 bytes")
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.New("password must contain at least one letter and one digit")
+	}
+
+	return nil
+}